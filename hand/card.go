@@ -1,8 +1,10 @@
 package hand
 
 import (
-	"errors"
+	"encoding/json"
+	"fmt"
 	"strings"
+	"unicode"
 )
 
 // A Rank represents the rank of a card.
@@ -89,6 +91,10 @@ const (
 	Clubs
 )
 
+const (
+	suitLettersStr = "shdc"
+)
+
 var (
 	suitsStr = []string{"♠", "♥", "♦", "♣"}
 	suitsMap = map[string]Suit{
@@ -96,6 +102,10 @@ var (
 		"♥": Hearts,
 		"♦": Diamonds,
 		"♣": Clubs,
+		"s": Spades,
+		"h": Hearts,
+		"d": Diamonds,
+		"c": Clubs,
 	}
 )
 
@@ -183,6 +193,12 @@ func (c Card) String() string {
 	return c.Rank().String() + c.Suit().String()
 }
 
+// letters returns a string in the format "4s"
+func (c Card) letters() string {
+	s := c.Suit()
+	return c.Rank().String() + suitLettersStr[s:s+1]
+}
+
 // MarshalText implements the encoding.TextMarshaler interface.
 // The text format is "4♠".
 func (c Card) MarshalText() ([]byte, error) {
@@ -190,22 +206,71 @@ func (c Card) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-// The card is expected to be in the format "4♠".
+// The card is expected to be in a format accepted by ParseCard.
 func (c *Card) UnmarshalText(text []byte) error {
-	s := string(text)
-	if len(s) <= 1 {
-		return errors.New("hand: invalid card text " + s)
+	card, err := ParseCard(string(text))
+	if err != nil {
+		return err
+	}
+	*c = card
+	return nil
+}
+
+// ParseCard returns the card for a string in the format "Kh", "K♥" or
+// "10h".  Ranks and suit letters aren't case sensitive and both "T" and
+// "10" are tens.
+func ParseCard(s string) (Card, error) {
+	runes := []rune(s)
+	if len(runes) < 2 {
+		return 0, fmt.Errorf("hand: invalid card %q", s)
+	}
+	rankStr := strings.ToUpper(string(runes[:len(runes)-1]))
+	if rankStr == "10" {
+		rankStr = "T"
 	}
-	rank := strings.Index(ranksStr, s[0:1])
-	if rank == -1 {
-		return errors.New("hand: invalid rank " + s[0:1])
+	rank := strings.Index(ranksStr, rankStr)
+	if len(rankStr) != 1 || rank == -1 {
+		return 0, fmt.Errorf("hand: invalid rank %q in card %q", rankStr, s)
 	}
-	suit, ok := suitsMap[s[1:]]
+	suitStr := strings.ToLower(string(runes[len(runes)-1:]))
+	suit, ok := suitsMap[suitStr]
 	if !ok {
-		return errors.New("hand: invalid suit " + s[1:])
+		return 0, fmt.Errorf("hand: invalid suit %q in card %q", suitStr, s)
 	}
-	*c = getCard(Rank(rank), suit)
-	return nil
+	return getCard(Rank(rank), suit), nil
+}
+
+// ParseCards returns the cards in a string of cards separated by spaces
+// or commas such as "Kh Qs 2d".  Each card is parsed with ParseCard.
+func ParseCards(s string) ([]Card, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	cards := []Card{}
+	for _, field := range fields {
+		card, err := ParseCard(field)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The json format is "4s".
+func (c Card) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.letters())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The card is expected to be in the format "4s" or "4♠".
+func (c *Card) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return c.UnmarshalText([]byte(s))
 }
 
 // Cards returns all 52 unshuffled cards
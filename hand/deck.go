@@ -1,6 +1,8 @@
 package hand
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"math/rand"
 	"strings"
 )
@@ -32,6 +34,21 @@ func (d *Deck) PopMulti(n int) []Card {
 	return cards
 }
 
+// Shuffle shuffles the cards left in the deck with the given random
+// source.
+func (d *Deck) Shuffle(r *rand.Rand) {
+	d.Cards = shuffleCards(r, d.Cards)
+}
+
+// Fingerprint returns a SHA-256 hash of the order of the cards in the deck
+// as hex.  Publishing the fingerprint before a deal and the deck after it
+// proves the deal wasn't changed in between.
+func (d *Deck) Fingerprint() string {
+	text, _ := d.MarshalText()
+	sum := sha256.Sum256(text)
+	return hex.EncodeToString(sum[:])
+}
+
 // String implements the fmt.Stringer interface
 func (d *Deck) String() string {
 	s := []string{}
@@ -41,21 +58,31 @@ func (d *Deck) String() string {
 	return strings.Join(s, ",")
 }
 
-// MarshalText implements the encoding.TextMarshaler interface
+// MarshalText implements the encoding.TextMarshaler interface.
+// The text format is "As,Kh,2c".
 func (d *Deck) MarshalText() (text []byte, err error) {
-	return []byte(d.String()), nil
+	s := []string{}
+	for _, c := range d.Cards {
+		s = append(s, c.letters())
+	}
+	return []byte(strings.Join(s, ",")), nil
 }
 
-// UnmarshalText implements the encoding.TextUnmarshaler interface
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// Cards may be in either the "As" or "A♠" format.
 func (d *Deck) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		d.Cards = []Card{}
+		return nil
+	}
 	strs := strings.Split(string(text), ",")
 	cards := make([]Card, len(strs))
 	for i, s := range strs {
-		var card *Card
+		var card Card
 		if err := card.UnmarshalText([]byte(s)); err != nil {
 			return err
 		}
-		cards[i] = *card
+		cards[i] = card
 	}
 	d.Cards = cards
 	return nil
@@ -76,9 +103,27 @@ type dealer struct {
 	r *rand.Rand
 }
 
+// SeededDealer is a dealer that records the seed of its random source so
+// the decks it generates can be reproduced by another dealer with the
+// same seed.
+type SeededDealer struct {
+	Seed int64
+	Dealer
+}
+
+// NewSeededDealer returns a dealer that generates shuffled decks with a
+// random source created with the given seed.
+func NewSeededDealer(seed int64) *SeededDealer {
+	return &SeededDealer{
+		Seed:   seed,
+		Dealer: NewDealer(rand.New(rand.NewSource(seed))),
+	}
+}
+
 func (d dealer) Deck() *Deck {
-	cards := shuffleCards(d.r, Cards())
-	return &Deck{Cards: cards}
+	deck := &Deck{Cards: Cards()}
+	deck.Shuffle(d.r)
+	return deck
 }
 
 func shuffleCards(r *rand.Rand, cards []Card) []Card {
@@ -0,0 +1,153 @@
+package hand
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Equity returns each player's share of the pot given their hole cards
+// and the board so far, with ties split between the players tied.  The
+// board is completed to five cards with every possible runout if there
+// are no more than iterations of them and with iterations random
+// runouts otherwise.  Equity panics if a card is used more than once or
+// the board has more than five cards.
+func Equity(holeCards [][]Card, board []Card, iterations int) []float64 {
+	shares, _ := EquityContext(context.Background(), holeCards, board, iterations)
+	return shares
+}
+
+// EquityContext is like Equity but stops early if the context is done,
+// returning the shares from the runouts scored so far along with the
+// context's error.
+func EquityContext(ctx context.Context, holeCards [][]Card, board []Card, iterations int) ([]float64, error) {
+	if len(board) > 5 {
+		panic("hand: board has more than five cards")
+	}
+	deck := unseen(append([][]Card{board}, holeCards...))
+	need := 5 - len(board)
+	shares := make([]float64, len(holeCards))
+	runouts := 0
+	done := ctx.Done()
+	score := func(runout []Card) bool {
+		select {
+		case <-done:
+			return false
+		default:
+		}
+		cards := append(append([]Card{}, board...), runout...)
+		var best []int
+		var bestHand *Hand
+		for i, hole := range holeCards {
+			h := New(append(append([]Card{}, hole...), cards...))
+			if bestHand == nil {
+				best, bestHand = []int{i}, h
+				continue
+			}
+			if cmp := h.CompareTo(bestHand); cmp > 0 {
+				best, bestHand = []int{i}, h
+			} else if cmp == 0 {
+				best = append(best, i)
+			}
+		}
+		for _, i := range best {
+			shares[i] += 1 / float64(len(best))
+		}
+		runouts++
+		return true
+	}
+	if combinations(len(deck), need) <= iterations {
+		forEachCombo(deck, need, score)
+	} else {
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		runout := make([]Card, need)
+		for n := 0; n < iterations; n++ {
+			// partially shuffle the deck to draw the runout
+			for i := 0; i < need; i++ {
+				j := i + r.Intn(len(deck)-i)
+				deck[i], deck[j] = deck[j], deck[i]
+				runout[i] = deck[i]
+			}
+			if !score(runout) {
+				break
+			}
+		}
+	}
+	if runouts > 0 {
+		for i := range shares {
+			shares[i] /= float64(runouts)
+		}
+	}
+	return shares, ctx.Err()
+}
+
+// Outs returns the cards that would improve a player's hand to each
+// higher ranking if dealt as the next card on the board.  A card is only
+// counted towards the best ranking it makes.  Outs panics if a card is
+// used more than once.
+func Outs(holeCards []Card, board []Card) map[Ranking][]Card {
+	cards := append(append([]Card{}, holeCards...), board...)
+	current := New(cards).Ranking()
+	outs := map[Ranking][]Card{}
+	for _, c := range unseen([][]Card{cards}) {
+		if r := New(append(cards, c)).Ranking(); r > current {
+			outs[r] = append(outs[r], c)
+		}
+	}
+	return outs
+}
+
+// unseen returns the cards not in any of the given sets of cards.  It
+// panics if a card is in more than one.
+func unseen(sets [][]Card) []Card {
+	used := map[Card]bool{}
+	for _, cards := range sets {
+		for _, c := range cards {
+			if used[c] {
+				panic("hand: card " + c.String() + " is used more than once")
+			}
+			used[c] = true
+		}
+	}
+	deck := []Card{}
+	for _, c := range Cards() {
+		if !used[c] {
+			deck = append(deck, c)
+		}
+	}
+	return deck
+}
+
+// combinations returns n choose k, or a number larger than any
+// iteration count if it would overflow.
+func combinations(n, k int) int {
+	c := 1
+	for i := 0; i < k; i++ {
+		c = c * (n - i) / (i + 1)
+		if c > 1<<31 {
+			return c
+		}
+	}
+	return c
+}
+
+// forEachCombo calls f with every combination of k cards until it
+// returns false.
+func forEachCombo(cards []Card, k int, f func([]Card) bool) {
+	combo := make([]Card, 0, k)
+	var recurse func(start int) bool
+	recurse = func(start int) bool {
+		if len(combo) == k {
+			return f(combo)
+		}
+		for i := start; i <= len(cards)-(k-len(combo)); i++ {
+			combo = append(combo, cards[i])
+			if !recurse(i + 1) {
+				return false
+			}
+			combo = combo[:len(combo)-1]
+		}
+		return true
+	}
+	recurse(0)
+}
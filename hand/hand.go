@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/notnil/joker/util"
 )
@@ -86,6 +87,7 @@ type Config struct {
 	ignoreStraights bool
 	ignoreFlushes   bool
 	aceIsLow        bool
+	shortDeck       bool
 }
 
 type configJSON struct {
@@ -93,6 +95,7 @@ type configJSON struct {
 	IgnoreStraights bool    `json:"ignoreStraights"`
 	IgnoreFlushes   bool    `json:"ignoreFlushes"`
 	AceIsLow        bool    `json:"aceIsLow"`
+	ShortDeck       bool    `json:"shortDeck,omitempty"`
 }
 
 // MarshalJSON implements the json.Marshaler interface.
@@ -102,6 +105,7 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 		IgnoreStraights: c.ignoreStraights,
 		IgnoreFlushes:   c.ignoreFlushes,
 		AceIsLow:        c.aceIsLow,
+		ShortDeck:       c.shortDeck,
 	}
 	return json.Marshal(m)
 }
@@ -116,6 +120,7 @@ func (c *Config) UnmarshalJSON(b []byte) error {
 	c.ignoreStraights = m.IgnoreStraights
 	c.ignoreFlushes = m.IgnoreFlushes
 	c.aceIsLow = m.AceIsLow
+	c.shortDeck = m.ShortDeck
 	return nil
 }
 
@@ -134,6 +139,13 @@ func AceToFiveLow(c *Config) {
 	c.ignoreFlushes = true
 }
 
+// ShortDeck configures NewHand to rank hands for short deck hold'em, in
+// which twos through fives are removed from the deck, a flush beats a
+// full house and A-6-7-8-9 is the lowest straight.
+func ShortDeck(c *Config) {
+	c.shortDeck = true
+}
+
 // A Hand is the highest poker hand derived from five or more cards.
 type Hand struct {
 	ranking     Ranking
@@ -156,10 +168,10 @@ func New(cards []Card, options ...func(*Config)) *Hand {
 	hands := []*Hand{}
 	for _, combo := range combos {
 		hand := handForFiveCards(combo, *c)
+		hand.config = c
 		hands = append(hands, hand)
 	}
 	hands = Sort(c.sorting, DESC, hands...)
-	hands[0].config = c
 	return hands[0]
 }
 
@@ -168,7 +180,9 @@ func (h *Hand) Ranking() Ranking {
 	return h.ranking
 }
 
-// Cards returns the five cards used in the best hand ranking for the hand.
+// Cards returns the five cards used in the best hand ranking for the hand,
+// such as the best five of seven, with the cards making the ranking first
+// followed by any kickers from highest to lowest.
 func (h *Hand) Cards() []Card {
 	return append([]Card{}, h.cards...)
 }
@@ -179,6 +193,96 @@ func (h *Hand) Description() string {
 	return h.description
 }
 
+// Breakdown is a structured description of a hand.  Ranks are the ranks
+// that make up the hand ranking, such as the pair ranks of two pair or
+// the high card of a straight, and Kickers are the remaining ranks that
+// break ties in the order they are compared.
+type Breakdown struct {
+	Ranking Ranking
+	Ranks   []Rank
+	Kickers []Rank
+}
+
+// Breakdown returns the ranks making up the hand and its kickers.
+func (h *Hand) Breakdown() Breakdown {
+	// made is the indexes of the cards that determine the ranks used
+	var made []int
+	switch h.ranking {
+	case HighCard, Pair, ThreeOfAKind, FourOfAKind,
+		Straight, StraightFlush, RoyalFlush:
+		made = []int{0}
+	case TwoPair:
+		made = []int{0, 2}
+	case FullHouse:
+		made = []int{0, 3}
+	case Flush:
+		made = []int{0, 1, 2, 3, 4}
+	}
+	b := Breakdown{Ranking: h.ranking}
+	for _, i := range made {
+		if i < len(h.cards) {
+			b.Ranks = append(b.Ranks, h.cards[i].Rank())
+		}
+	}
+	// straights use all five cards so there are no kickers
+	if h.ranking == Straight || h.ranking == StraightFlush || h.ranking == RoyalFlush {
+		return b
+	}
+	used := map[Rank]bool{}
+	for _, r := range b.Ranks {
+		used[r] = true
+	}
+	for _, c := range h.cards {
+		if r := c.Rank(); !used[r] {
+			used[r] = true
+			b.Kickers = append(b.Kickers, r)
+		}
+	}
+	return b
+}
+
+// String returns a description of the hand with its kickers such as
+// "pair of aces with king, nine, four kickers".
+func (b Breakdown) String() string {
+	if len(b.Ranks) == 0 {
+		return ""
+	}
+	desc := ""
+	switch b.Ranking {
+	case HighCard:
+		desc = fmt.Sprintf("high card %v high", b.Ranks[0].singularName())
+	case Pair:
+		desc = fmt.Sprintf("pair of %v", b.Ranks[0].pluralName())
+	case TwoPair:
+		desc = fmt.Sprintf("two pair %v and %v", b.Ranks[0].pluralName(), b.Ranks[1].pluralName())
+	case ThreeOfAKind:
+		desc = fmt.Sprintf("three of a kind %v", b.Ranks[0].pluralName())
+	case Straight:
+		desc = fmt.Sprintf("straight %v high", b.Ranks[0].singularName())
+	case Flush:
+		desc = fmt.Sprintf("flush %v high", b.Ranks[0].singularName())
+	case FullHouse:
+		desc = fmt.Sprintf("full house %v full of %v", b.Ranks[0].pluralName(), b.Ranks[1].pluralName())
+	case FourOfAKind:
+		desc = fmt.Sprintf("four of a kind %v", b.Ranks[0].pluralName())
+	case StraightFlush:
+		desc = fmt.Sprintf("straight flush %v high", b.Ranks[0].singularName())
+	case RoyalFlush:
+		desc = "royal flush"
+	}
+	if len(b.Kickers) == 0 {
+		return desc
+	}
+	kickers := []string{}
+	for _, r := range b.Kickers {
+		kickers = append(kickers, r.singularName())
+	}
+	if len(kickers) == 1 {
+		return fmt.Sprintf("%s with %s kicker", desc, kickers[0])
+	}
+	return fmt.Sprintf("%s with %s kickers", desc, strings.Join(kickers, ", "))
+}
+
 // String returns the description followed by the cards used.
 func (h *Hand) String() string {
 	return fmt.Sprintf("%s %v", h.Description(), h.Cards())
@@ -189,11 +293,11 @@ func (h *Hand) String() string {
 // are equal.
 func (h *Hand) CompareTo(o *Hand) int {
 	if h.Ranking() != o.Ranking() {
-		return int(h.Ranking()) - int(o.Ranking())
+		return h.rankingValue() - o.rankingValue()
 	}
 	hCards := h.Cards()
 	oCards := o.Cards()
-	for i := 0; i < 5; i++ {
+	for i := 0; i < len(hCards) && i < len(oCards); i++ {
 		hCard, oCard := hCards[i], oCards[i]
 		hIndex, oIndex := hCard.Rank(), oCard.Rank()
 		if hIndex != oIndex {
@@ -203,6 +307,20 @@ func (h *Hand) CompareTo(o *Hand) int {
 	return 0
 }
 
+// rankingValue returns the strength of the hand's ranking, which differs
+// from the Ranking's order in short deck where a flush beats a full house.
+func (h *Hand) rankingValue() int {
+	if h.config != nil && h.config.shortDeck {
+		switch h.ranking {
+		case Flush:
+			return int(FullHouse)
+		case FullHouse:
+			return int(Flush)
+		}
+	}
+	return int(h.ranking)
+}
+
 type handJSON struct {
 	Ranking     Ranking `json:"ranking"`
 	Cards       []Card  `json:"cards"`
@@ -212,7 +330,7 @@ type handJSON struct {
 
 // MarshalJSON implements the json.Marshaler interface.
 // The json format is:
-// {"ranking":10,"cards":["A♠","K♠","Q♠","J♠","T♠"],"description":"royal flush","config":{"sorting":1,"ignoreStraights":false,"ignoreFlushes":false,"aceIsLow":false}}
+// {"ranking":10,"cards":["As","Ks","Qs","Js","Ts"],"description":"royal flush","config":{"sorting":1,"ignoreStraights":false,"ignoreFlushes":false,"aceIsLow":false}}
 func (h *Hand) MarshalJSON() ([]byte, error) {
 	m := &handJSON{
 		Ranking:     h.ranking,
@@ -225,7 +343,7 @@ func (h *Hand) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
 //  The json format is:
-// {"ranking":10,"cards":["A♠","K♠","Q♠","J♠","T♠"],"description":"royal flush","config":{"sorting":1,"ignoreStraights":false,"ignoreFlushes":false,"aceIsLow":false}}
+// {"ranking":10,"cards":["As","Ks","Qs","Js","Ts"],"description":"royal flush","config":{"sorting":1,"ignoreStraights":false,"ignoreFlushes":false,"aceIsLow":false}}
 func (h *Hand) UnmarshalJSON(b []byte) error {
 	m := &handJSON{}
 	if err := json.Unmarshal(b, m); err != nil {
@@ -236,6 +354,7 @@ func (h *Hand) UnmarshalJSON(b []byte) error {
 		c.ignoreStraights = m.Config.ignoreStraights
 		c.ignoreFlushes = m.Config.ignoreFlushes
 		c.aceIsLow = m.Config.aceIsLow
+		c.shortDeck = m.Config.shortDeck
 	}
 	cp := New(m.Cards, f)
 	h.ranking = cp.ranking
@@ -320,7 +439,7 @@ var (
 		r: HighCard,
 		vFunc: func(cards []Card, c Config) bool {
 			flush := hasFlush(cards)
-			straight := hasStraight(cards)
+			straight := hasStraight(cards, c)
 			pairs := hasPairs(cards, []int{1, 1, 1, 1, 1})
 			if !c.ignoreStraights {
 				pairs = pairs && !straight
@@ -377,7 +496,7 @@ var (
 				return false
 			}
 			flush := hasFlush(cards)
-			straight := hasStraight(cards)
+			straight := hasStraight(cards, c)
 			return !flush && straight
 		},
 		dFunc: func(cards []Card) string {
@@ -394,7 +513,7 @@ var (
 			}
 
 			flush := hasFlush(cards)
-			straight := hasStraight(cards)
+			straight := hasStraight(cards, c)
 			return flush && !straight
 		},
 		dFunc: func(cards []Card) string {
@@ -433,7 +552,7 @@ var (
 				return false
 			}
 			flush := hasFlush(cards)
-			straight := hasStraight(cards)
+			straight := hasStraight(cards, c)
 			return cards[0].Rank() != Ace && flush && straight
 		},
 		dFunc: func(cards []Card) string {
@@ -449,7 +568,7 @@ var (
 				return false
 			}
 			flush := hasFlush(cards)
-			straight := hasStraight(cards)
+			straight := hasStraight(cards, c)
 			return cards[0].Rank() == Ace && flush && straight
 		},
 		dFunc: func(cards []Card) string {
@@ -488,7 +607,7 @@ func formCards(cards []Card, c Config) []Card {
 		}
 	}
 	// check for low straight
-	return formLowStraight(formed)
+	return formLowStraight(formed, c)
 }
 
 func hasPairs(cards []Card, pairNums []int) bool {
@@ -517,7 +636,7 @@ func hasFlush(cards []Card) bool {
 	return has
 }
 
-func hasStraight(cards []Card) bool {
+func hasStraight(cards []Card, c Config) bool {
 	if len(cards) != 5 {
 		return false
 	}
@@ -528,26 +647,37 @@ func hasStraight(cards []Card) bool {
 		straight = straight && (lastIndex == index+1)
 		lastIndex = index
 	}
-	return straight || hasLowStraight(cards)
+	return straight || hasLowStraight(cards, c)
+}
+
+// lowStraightRanks returns the ranks below the ace in the lowest
+// straight, which is A-6-7-8-9 in short deck.
+func lowStraightRanks(c Config) []Rank {
+	if c.shortDeck {
+		return []Rank{Nine, Eight, Seven, Six}
+	}
+	return []Rank{Five, Four, Three, Two}
 }
 
-func hasLowStraight(cards []Card) bool {
-	return cards[0].Rank() == Five &&
-		cards[1].Rank() == Four &&
-		cards[2].Rank() == Three &&
-		cards[3].Rank() == Two &&
+func hasLowStraight(cards []Card, c Config) bool {
+	ranks := lowStraightRanks(c)
+	return cards[0].Rank() == ranks[0] &&
+		cards[1].Rank() == ranks[1] &&
+		cards[2].Rank() == ranks[2] &&
+		cards[3].Rank() == ranks[3] &&
 		cards[4].Rank() == Ace
 }
 
-func formLowStraight(cards []Card) []Card {
+func formLowStraight(cards []Card, c Config) []Card {
 	if len(cards) < 5 {
 		return cards
 	}
+	ranks := lowStraightRanks(c)
 	has := cards[0].Rank() == Ace &&
-		cards[1].Rank() == Five &&
-		cards[2].Rank() == Four &&
-		cards[3].Rank() == Three &&
-		cards[4].Rank() == Two
+		cards[1].Rank() == ranks[0] &&
+		cards[2].Rank() == ranks[1] &&
+		cards[3].Rank() == ranks[2] &&
+		cards[4].Rank() == ranks[3]
 	if has {
 		cards = []Card{cards[1], cards[2], cards[3], cards[4], cards[0]}
 	}
@@ -1,9 +1,12 @@
 package hand_test
 
 import (
+	"context"
 	"encoding/json"
 	"math/rand"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/notnil/joker/hand"
 	. "github.com/notnil/joker/jokertest"
@@ -239,10 +242,169 @@ func TestDeck(t *testing.T) {
 	if l != 50 {
 		t.Fatalf("After Pop() deck len = %d; want %d", l, 50)
 	}
+	b, err := json.Marshal(deck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &hand.Deck{}
+	if err := json.Unmarshal(b, restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.String() != deck.String() {
+		t.Fatalf("expected deck %s but got %s", deck, restored)
+	}
+}
+
+func TestDeckFingerprint(t *testing.T) {
+	a := hand.NewSeededDealer(42)
+	b := hand.NewSeededDealer(a.Seed)
+	for i := 0; i < 3; i++ {
+		deckA, deckB := a.Deck(), b.Deck()
+		if deckA.String() != deckB.String() || deckA.Fingerprint() != deckB.Fingerprint() {
+			t.Fatalf("deck %d: expected the same seed to deal %s but got %s", i, deckA, deckB)
+		}
+	}
+	deck := hand.NewSeededDealer(42).Deck()
+	if other := hand.NewSeededDealer(43).Deck(); other.Fingerprint() == deck.Fingerprint() {
+		t.Fatal("expected a different seed to give a different fingerprint")
+	}
+	fingerprint := deck.Fingerprint()
+	deck.Shuffle(rand.New(rand.NewSource(0)))
+	if len(deck.Cards) != 52 || deck.Fingerprint() == fingerprint {
+		t.Fatalf("expected shuffling to change the fingerprint of %s", deck)
+	}
+}
+
+func TestCommitShuffle(t *testing.T) {
+	dealer := hand.NewCommitDealer()
+	deck := dealer.Deck()
+	commitment := dealer.CommitShuffle()
+	seed, cards := dealer.RevealShuffle()
+	if !reflect.DeepEqual(cards, deck.Cards) {
+		t.Fatalf("expected the revealed deck %v to be the one dealt %v", cards, deck.Cards)
+	}
+	if !(hand.Shuffle{Seed: seed, Deck: cards}).Verify(commitment) {
+		t.Fatal("expected the revealed shuffle to verify")
+	}
+	tampered := append([]hand.Card{}, cards...)
+	tampered[0], tampered[1] = tampered[1], tampered[0]
+	if (hand.Shuffle{Seed: seed, Deck: tampered}).Verify(commitment) {
+		t.Fatal("expected a tampered deck not to verify")
+	}
+	// a dealer committing to a stacked deck is caught by the seed
+	stacked := hand.Shuffle{Seed: seed, Deck: tampered}
+	if stacked.Verify(stacked.Commitment()) {
+		t.Fatal("expected a deck not shuffled from the seed not to verify")
+	}
+	if (hand.Shuffle{Seed: seed + "0", Deck: cards}).Verify(commitment) {
+		t.Fatal("expected a different seed not to verify")
+	}
+	dealer.Deck()
+	if dealer.CommitShuffle() == commitment {
+		t.Fatal("expected the next deck to have a new commitment")
+	}
+}
+
+func TestCardJSON(t *testing.T) {
+	for _, card := range hand.Cards() {
+		b, err := json.Marshal(card)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) != 4 {
+			t.Fatalf("expected %v to marshal to a two letter string but got %s", card, b)
+		}
+		var restored hand.Card
+		if err := json.Unmarshal(b, &restored); err != nil {
+			t.Fatal(err)
+		}
+		if restored != card {
+			t.Fatalf("expected %v but got %v", card, restored)
+		}
+	}
+	b, err := json.Marshal(Cards("As", "Td", "2c", "9h"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `["As","Td","2c","9h"]`; string(b) != expected {
+		t.Fatalf("expected json %s but got %s", expected, b)
+	}
+	var card hand.Card
+	if err := json.Unmarshal([]byte(`"A♠"`), &card); err != nil || card != hand.AceSpades {
+		t.Fatalf("expected A♠ to unmarshal to %v but got %v with error %v", hand.AceSpades, card, err)
+	}
+	for _, s := range []string{`"A"`, `"Ax"`, `"1s"`, `""`, `12`} {
+		if err := json.Unmarshal([]byte(s), &card); err == nil {
+			t.Fatalf("expected an error unmarshaling %s", s)
+		}
+	}
+}
+
+func TestParseCards(t *testing.T) {
+	tests := []struct {
+		s     string
+		cards []hand.Card
+	}{
+		{"Kh", []hand.Card{hand.KingHearts}},
+		{"kh Qs 2d", []hand.Card{hand.KingHearts, hand.QueenSpades, hand.TwoDiamonds}},
+		{"Td 10d tD", []hand.Card{hand.TenDiamonds, hand.TenDiamonds, hand.TenDiamonds}},
+		{"A♠,  9♣", []hand.Card{hand.AceSpades, hand.NineClubs}},
+		{"", []hand.Card{}},
+	}
+	for _, test := range tests {
+		cards, err := hand.ParseCards(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(cards, test.cards) {
+			t.Fatalf("expected %q to parse to %v but got %v", test.s, test.cards, cards)
+		}
+	}
+	for _, s := range []string{"K", "Kx", "1h", "11h", "Kh Q", "KhQs", "h"} {
+		if _, err := hand.ParseCards(s); err == nil {
+			t.Fatalf("expected an error parsing %q", s)
+		}
+	}
+	if _, err := hand.ParseCard("Kh Qs"); err == nil {
+		t.Fatal("expected an error parsing two cards as one")
+	}
+}
+
+func TestBreakdown(t *testing.T) {
+	tests := []struct {
+		cards       []hand.Card
+		ranks       []hand.Rank
+		kickers     []hand.Rank
+		description string
+	}{
+		{Cards("Ks", "Qs", "Js", "As", "9d"), []hand.Rank{hand.Ace}, []hand.Rank{hand.King, hand.Queen, hand.Jack, hand.Nine},
+			"high card ace high with king, queen, jack, nine kickers"},
+		{Cards("Ks", "Qh", "Qs", "Js", "9d"), []hand.Rank{hand.Queen}, []hand.Rank{hand.King, hand.Jack, hand.Nine},
+			"pair of queens with king, jack, nine kickers"},
+		{Cards("Ks", "Kh", "Ts", "Td", "4c", "2c", "3d"), []hand.Rank{hand.King, hand.Ten}, []hand.Rank{hand.Four},
+			"two pair kings and tens with four kicker"},
+		{Cards("Ad", "Td", "7d", "4d", "2d", "As", "Ac"), []hand.Rank{hand.Ace, hand.Ten, hand.Seven, hand.Four, hand.Two}, nil,
+			"flush ace high"},
+		{Cards("6s", "5h", "4s", "3h", "2d"), []hand.Rank{hand.Six}, nil,
+			"straight six high"},
+		{Cards("6s", "6h", "6d", "Ks", "Kh"), []hand.Rank{hand.Six, hand.King}, nil,
+			"full house sixes full of kings"},
+		{Cards("9s", "9h", "9d", "9c", "2h", "Ah"), []hand.Rank{hand.Nine}, []hand.Rank{hand.Ace},
+			"four of a kind nines with ace kicker"},
+	}
+	for _, test := range tests {
+		b := hand.New(test.cards).Breakdown()
+		if !reflect.DeepEqual(b.Ranks, test.ranks) || !reflect.DeepEqual(b.Kickers, test.kickers) {
+			t.Fatalf("expected ranks %v and kickers %v but got %+v", test.ranks, test.kickers, b)
+		}
+		if b.String() != test.description {
+			t.Fatalf("expected %q but got %q", test.description, b.String())
+		}
+	}
 }
 
 func TestHandJSON(t *testing.T) {
-	jsonStr := `{"ranking":10,"cards":["A♠","K♠","Q♠","J♠","T♠"],"description":"royal flush","config":{"sorting":1,"ignoreStraights":false,"ignoreFlushes":false,"aceIsLow":false}}`
+	jsonStr := `{"ranking":10,"cards":["As","Ks","Qs","Js","Ts"],"description":"royal flush","config":{"sorting":1,"ignoreStraights":false,"ignoreFlushes":false,"aceIsLow":false}}`
 	h := &hand.Hand{}
 	if err := json.Unmarshal([]byte(jsonStr), h); err != nil {
 		t.Fatal(err)
@@ -264,3 +426,156 @@ func BenchmarkHandCreation(b *testing.B) {
 		hand.New(cards)
 	}
 }
+
+func TestEquity(t *testing.T) {
+	tests := []struct {
+		holeCards  [][]hand.Card
+		board      []hand.Card
+		iterations int
+		equity     []float64
+		tolerance  float64
+	}{
+		// AA vs KK preflop is about 82% to 18%
+		{[][]hand.Card{Cards("As", "Ah"), Cards("Ks", "Kh")}, nil, 5000, []float64{0.82, 0.18}, 0.03},
+		// AKs vs 22 is close to a coin flip
+		{[][]hand.Card{Cards("As", "Ks"), Cards("2c", "2d")}, nil, 5000, []float64{0.50, 0.50}, 0.04},
+		// a set on the turn against an open ended straight draw has the
+		// straight's 8 outs of 44 cards against it, enumerated exactly
+		{[][]hand.Card{Cards("9c", "9d"), Cards("Th", "Jh")}, Cards("9s", "8c", "2d", "3s"), 1000, []float64{36.0 / 44, 8.0 / 44}, 1e-9},
+		// the board plays for both players so they split
+		{[][]hand.Card{Cards("2c", "3d"), Cards("4c", "5d")}, Cards("As", "Ks", "Qs", "Js", "Ts"), 1000, []float64{0.5, 0.5}, 1e-9},
+	}
+	for i, test := range tests {
+		equity := hand.Equity(test.holeCards, test.board, test.iterations)
+		if len(equity) != len(test.equity) {
+			t.Fatalf("test %d: expected %d equities but got %v", i, len(test.equity), equity)
+		}
+		for j := range equity {
+			if diff := equity[j] - test.equity[j]; diff > test.tolerance || diff < -test.tolerance {
+				t.Fatalf("test %d: expected equity %v but got %v", i, test.equity, equity)
+			}
+		}
+	}
+}
+
+func TestEquityContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	holeCards := [][]hand.Card{Cards("As", "Ah"), Cards("Ks", "Kh")}
+	// one fewer than the 1,712,304 runouts forces random sampling, which
+	// takes far longer than the deadline
+	equity, err := hand.EquityContext(ctx, holeCards, nil, 1712303)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to be exceeded but got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected a prompt return after cancelling but took %v", elapsed)
+	}
+	// the partial result is from however many runouts were scored before
+	// the deadline, so only its shape is certain
+	if len(equity) != 2 {
+		t.Fatalf("expected a share for each player but got %v", equity)
+	}
+	if sum := equity[0] + equity[1]; sum != 0 && (sum < 0.999 || sum > 1.001) {
+		t.Fatalf("expected partial shares adding up to one but got %v", equity)
+	}
+
+	// exact enumeration stops too
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := hand.EquityContext(ctx, holeCards, Cards("2c", "7d", "9h"), 1000); err != context.Canceled {
+		t.Fatalf("expected the enumeration to be canceled but got %v", err)
+	}
+}
+
+func TestOuts(t *testing.T) {
+	tests := []struct {
+		holeCards []hand.Card
+		board     []hand.Card
+		ranking   hand.Ranking
+		outs      int
+	}{
+		// open ended straight draw
+		{Cards("8h", "9c"), Cards("Ts", "Jd", "2c"), hand.Straight, 8},
+		// flush draw
+		{Cards("Ah", "Kh"), Cards("2h", "7h", "Jc"), hand.Flush, 9},
+		// gutshot straight draw
+		{Cards("8h", "9c"), Cards("Jd", "Qs", "3c"), hand.Straight, 4},
+		// a pair of aces has twelve outs to two pair and two to trips
+		{Cards("Ah", "Kc"), Cards("As", "7d", "2c", "9h"), hand.TwoPair, 12},
+		{Cards("Ah", "Kc"), Cards("As", "7d", "2c", "9h"), hand.ThreeOfAKind, 2},
+	}
+	for i, test := range tests {
+		outs := hand.Outs(test.holeCards, test.board)
+		if n := len(outs[test.ranking]); n != test.outs {
+			t.Fatalf("test %d: expected %d outs to %v but got %v", i, test.outs, test.ranking, outs[test.ranking])
+		}
+	}
+}
+
+func TestPreFlopStrength(t *testing.T) {
+	if s := hand.PreFlopStrength(Cards("As", "Ah")); s != 1 {
+		t.Fatalf("expected aces to rank highest but got %v", s)
+	}
+	if s := hand.PreFlopStrength(Cards("2d", "7c")); s > 0.05 {
+		t.Fatalf("expected seven two offsuit near the bottom but got %v", s)
+	}
+	if s := hand.PreFlopStrength(Cards("3h", "2c")); s != 0 {
+		t.Fatalf("expected three two offsuit to rank lowest but got %v", s)
+	}
+	// the order of the cards doesn't matter and suited hands are stronger
+	aks, ako := hand.PreFlopStrength(Cards("Ks", "As")), hand.PreFlopStrength(Cards("Ah", "Kd"))
+	if aks != hand.PreFlopStrength(Cards("As", "Ks")) || aks <= ako {
+		t.Fatalf("expected ace king suited %v to beat offsuit %v", aks, ako)
+	}
+}
+
+func TestBestFiveOfSeven(t *testing.T) {
+	tests := []struct {
+		cards []hand.Card
+		best  []hand.Card
+	}{
+		// full house uses the higher trips and the best pair
+		{Cards("Kh", "Kd", "Ks", "7c", "7d", "2c", "2h"), Cards("Kh", "Kd", "Ks", "7c", "7d")},
+		// two pair uses the two highest pairs and the best kicker
+		{Cards("Ah", "Ad", "9s", "9c", "4d", "4h", "Qc"), Cards("Ah", "Ad", "9s", "9c", "Qc")},
+		// straight uses the highest run
+		{Cards("4c", "5d", "6h", "7s", "8c", "9d", "Kh"), Cards("9d", "8c", "7s", "6h", "5d")},
+	}
+	for i, test := range tests {
+		cards := hand.New(test.cards).Cards()
+		if len(cards) != 5 {
+			t.Fatalf("test %d: expected five cards but got %v", i, cards)
+		}
+		if !reflect.DeepEqual(cards, test.best) {
+			t.Fatalf("test %d: expected %v but got %v", i, test.best, cards)
+		}
+	}
+}
+
+func TestShortDeck(t *testing.T) {
+	flush := hand.New(Cards("Ah", "Kh", "9h", "7h", "6h"), hand.ShortDeck)
+	fullHouse := hand.New(Cards("9c", "9d", "9s", "6c", "6d"), hand.ShortDeck)
+	if flush.CompareTo(fullHouse) <= 0 || fullHouse.CompareTo(flush) >= 0 {
+		t.Fatal("expected a flush to beat a full house in short deck")
+	}
+	if flush, fullHouse := hand.New(flush.Cards()), hand.New(fullHouse.Cards()); flush.CompareTo(fullHouse) >= 0 {
+		t.Fatal("expected a full house to beat a flush")
+	}
+
+	wheel := hand.New(Cards("As", "6h", "7d", "8c", "9s", "Kd", "Kc"), hand.ShortDeck)
+	if wheel.Ranking() != hand.Straight {
+		t.Fatalf("expected A-6-7-8-9 to be a straight but got %v", wheel)
+	}
+	if expected := Cards("9s", "8c", "7d", "6h", "As"); !reflect.DeepEqual(wheel.Cards(), expected) {
+		t.Fatalf("expected %v but got %v", expected, wheel.Cards())
+	}
+	higher := hand.New(Cards("6h", "7d", "8c", "9s", "Td"), hand.ShortDeck)
+	if wheel.CompareTo(higher) >= 0 {
+		t.Fatal("expected A-6-7-8-9 to be the lowest straight")
+	}
+	if h := hand.New(Cards("As", "6h", "7d", "8c", "9s")); h.Ranking() == hand.Straight {
+		t.Fatal("expected A-6-7-8-9 not to be a straight outside short deck")
+	}
+}
@@ -0,0 +1,57 @@
+package hand
+
+// startingHands are the 169 distinct starting hands in hold'em from
+// strongest to weakest, ranked by their equity against a random hand.
+var startingHands = []string{
+	"AA", "KK", "QQ", "JJ", "TT", "99", "88", "77", "AKs", "AQs", "AJs", "AKo", "ATs",
+	"AQo", "AJo", "KQs", "A9s", "66", "ATo", "KJs", "A8s", "KTs", "KQo", "A7s", "QJs", "A9o",
+	"KJo", "55", "K9s", "A8o", "KTo", "A6s", "A5s", "A7o", "QTs", "A4s", "K8s", "A3s", "QJo",
+	"Q9s", "QTo", "A6o", "K7s", "JTs", "A5o", "A4o", "K9o", "A2s", "44", "K6s", "Q8s", "K5s",
+	"K8o", "JTo", "K4s", "Q9o", "A3o", "J9s", "A2o", "K7o", "K3s", "T9s", "Q7s", "J8s", "K6o",
+	"Q6s", "J9o", "K5o", "Q8o", "33", "K2s", "K4o", "J7s", "Q5s", "T8s", "Q4s", "K3o", "J8o",
+	"Q7o", "Q3s", "Q6o", "T9o", "98s", "T7s", "22", "J7o", "J6s", "K2o", "Q5o", "T8o", "Q2s",
+	"J5s", "Q4o", "97s", "J4s", "T6s", "98o", "87s", "Q3o", "J3s", "T5s", "T7o", "96s", "J2s",
+	"Q2o", "J6o", "J5o", "J4o", "97o", "86s", "T4s", "T3s", "T6o", "95s", "J3o", "87o", "85s",
+	"T2s", "76s", "96o", "J2o", "T5o", "94s", "75s", "T4o", "65s", "93s", "84s", "T3o", "95o",
+	"92s", "86o", "76o", "85o", "74s", "T2o", "64s", "54s", "83s", "82s", "94o", "73s", "75o",
+	"65o", "63s", "93o", "53s", "92o", "84o", "43s", "74o", "72s", "64o", "54o", "62s", "83o",
+	"52s", "82o", "42s", "73o", "63o", "53o", "32s", "43o", "72o", "62o", "52o", "42o", "32o",
+}
+
+// startingHandRanks maps each starting hand to its position in
+// startingHands.
+var startingHandRanks = map[string]int{}
+
+func init() {
+	for i, s := range startingHands {
+		startingHandRanks[s] = i
+	}
+}
+
+// PreFlopStrength returns the relative strength of two hole cards before
+// the flop from 1 for a pair of aces to 0 for the weakest starting hand,
+// based on the equity of each of the 169 distinct starting hands against
+// a random hand.  PreFlopStrength panics if it isn't given two different
+// cards.
+func PreFlopStrength(holeCards []Card) float64 {
+	if len(holeCards) != 2 || holeCards[0] == holeCards[1] {
+		panic("hand: pre-flop strength needs two different hole cards")
+	}
+	i := startingHandRanks[startingHand(holeCards[0], holeCards[1])]
+	return 1 - float64(i)/float64(len(startingHands)-1)
+}
+
+// startingHand returns the name of the starting hand such as "AKs" for
+// suited ace king, "AKo" for offsuit and "AA" for a pair.
+func startingHand(a, b Card) string {
+	if a.Rank() < b.Rank() {
+		a, b = b, a
+	}
+	switch {
+	case a.Rank() == b.Rank():
+		return a.Rank().String() + b.Rank().String()
+	case a.Suit() == b.Suit():
+		return a.Rank().String() + b.Rank().String() + "s"
+	}
+	return a.Rank().String() + b.Rank().String() + "o"
+}
@@ -0,0 +1,88 @@
+package hand
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand"
+)
+
+// Shuffle is the seed a deck was shuffled from and the resulting order of
+// the cards.  Its commitment can be published before the deck is dealt
+// and the shuffle revealed afterwards so players can verify the deal.
+type Shuffle struct {
+	Seed string
+	Deck []Card
+}
+
+// Commitment returns a SHA-256 hash of the seed and the deck as hex, which
+// reveals nothing about the deck until the shuffle itself is revealed.
+func (s Shuffle) Commitment() string {
+	deck := &Deck{Cards: s.Deck}
+	text, _ := deck.MarshalText()
+	sum := sha256.Sum256(append([]byte(s.Seed+":"), text...))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify returns whether the deck is the one shuffled from the seed and
+// matches the commitment published before the deal.
+func (s Shuffle) Verify(commitment string) bool {
+	if s.Commitment() != commitment {
+		return false
+	}
+	expected := seededShuffle(s.Seed)
+	if len(expected) != len(s.Deck) {
+		return false
+	}
+	for i, c := range expected {
+		if s.Deck[i] != c {
+			return false
+		}
+	}
+	return true
+}
+
+// CommitDealer is a dealer that shuffles each deck from a new seed and
+// commits to the shuffle before the deck is dealt.
+type CommitDealer struct {
+	last Shuffle
+}
+
+// NewCommitDealer returns a dealer that draws the seed for each deck from
+// crypto/rand.  The seeds are revealed after each hand, so drawing them
+// from a seeded source would let players predict the decks to come.
+func NewCommitDealer() *CommitDealer {
+	return &CommitDealer{}
+}
+
+// Deck implements the Dealer interface.  It panics if no seed can be read
+// from crypto/rand.
+func (d *CommitDealer) Deck() *Deck {
+	b := make([]byte, 16)
+	if _, err := crand.Read(b); err != nil {
+		panic(err)
+	}
+	seed := hex.EncodeToString(b)
+	d.last = Shuffle{Seed: seed, Deck: seededShuffle(seed)}
+	return &Deck{Cards: append([]Card{}, d.last.Deck...)}
+}
+
+// CommitShuffle returns the commitment to the last deck generated.
+func (d *CommitDealer) CommitShuffle() string {
+	return d.last.Commitment()
+}
+
+// RevealShuffle returns the seed and order of the last deck generated,
+// which should only be revealed once the deck has been dealt.
+func (d *CommitDealer) RevealShuffle() (string, []Card) {
+	return d.last.Seed, append([]Card{}, d.last.Deck...)
+}
+
+// seededShuffle returns the cards shuffled by a random source seeded from
+// a hash of the seed.
+func seededShuffle(seed string) []Card {
+	sum := sha256.Sum256([]byte(seed))
+	src := rand.NewSource(int64(binary.BigEndian.Uint64(sum[:8])))
+	return shuffleCards(rand.New(src), Cards())
+}
@@ -60,7 +60,10 @@ func Cards(list ...string) []hand.Card {
 }
 
 // Dealer returns a hand.Dealer that generates decks that will pop
-// cards in the order of the cards given.
+// cards in the order of the cards given.  A table deals each seat its
+// hole cards in seat order before the board, burning a card before each
+// street, so the cards can script a hand such as one where the first
+// seat has pocket aces.
 func Dealer(cards []hand.Card) hand.Dealer {
 	return &deck{cards: cards}
 }
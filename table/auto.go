@@ -0,0 +1,117 @@
+package table
+
+import (
+	"errors"
+
+	"github.com/notnil/joker/hand"
+)
+
+// Strategy chooses an action for the active player from the state as
+// they see it and their legal actions.
+type Strategy func(s State, legal []LegalAction) Action
+
+// AutoPlayer acts for the active player by a strategy, such as to fill
+// seats or drive tests.  It only ever takes legal actions: one its
+// strategy picks that isn't legal is replaced by a check, or a fold if
+// chips are owed, and the chips of a bet or raise are kept within the
+// bounds allowed.
+type AutoPlayer struct {
+	// Strategy chooses the actions and defaults to CallingStation
+	Strategy Strategy
+}
+
+// Choose returns the action the strategy chooses given the state and the
+// active player's legal actions.
+func (p AutoPlayer) Choose(s State, legal []LegalAction) Action {
+	strategy := p.Strategy
+	if strategy == nil {
+		strategy = CallingStation
+	}
+	a := strategy(s, legal)
+	for _, l := range legal {
+		if l.Type != a.Type {
+			continue
+		}
+		if a.Type != Bet && a.Type != Raise {
+			return Action{Type: a.Type}
+		}
+		if a.Chips < l.Min {
+			a.Chips = l.Min
+		}
+		if a.Chips > l.Max {
+			a.Chips = l.Max
+		}
+		return a
+	}
+	return passive(legal)
+}
+
+// Act chooses and takes an action for the active player.  The table's
+// state mustn't be changed by anyone else while it does.
+func (p AutoPlayer) Act(t *Table) error {
+	legal := t.LegalActionsDetailed()
+	if legal == nil {
+		return errors.New("table: no hand in progress")
+	}
+	s := t.State()
+	return t.Act(p.Choose(t.StateFor(s.Active.ID), legal))
+}
+
+// CallingStation checks when it can and calls otherwise, never betting,
+// raising or folding.
+func CallingStation(s State, legal []LegalAction) Action {
+	for _, l := range legal {
+		if l.Type == Check || l.Type == Call {
+			return Action{Type: l.Type}
+		}
+	}
+	return passive(legal)
+}
+
+// Tight plays only strong hands.  Before the flop in hold'em it raises
+// the best starting hands by the minimum, calls with good ones and
+// otherwise folds unless it can check.  Once it can make a hand it bets
+// or raises the minimum with two pair or better, calls with a pair and
+// checks or folds with less.
+func Tight(s State, legal []LegalAction) Action {
+	strength := 0.0
+	switch hole := s.Active.Cards; {
+	case s.Round == PreFlop && len(hole) == 2:
+		strength = hand.PreFlopStrength(hole)
+	case s.Options.Variant == OmahaHi || s.Options.Variant == OmahaHiLo:
+		strength = madeStrength(bestOmahaHand(hole, s.Cards))
+	default:
+		strength = madeStrength(hand.New(append(append([]hand.Card{}, hole...), s.Cards...)))
+	}
+	for _, l := range legal {
+		switch {
+		case strength >= 0.85 && (l.Type == Bet || l.Type == Raise):
+			return Action{Type: l.Type, Chips: l.Min}
+		case strength >= 0.6 && l.Type == Call:
+			return Action{Type: Call}
+		}
+	}
+	return passive(legal)
+}
+
+// madeStrength rates a made hand on the scale of hand.PreFlopStrength:
+// enough to raise with two pair or better and to call with a pair.
+func madeStrength(h *hand.Hand) float64 {
+	switch {
+	case h.Ranking() >= hand.TwoPair:
+		return 1
+	case h.Ranking() == hand.Pair:
+		return 0.6
+	}
+	return 0
+}
+
+// passive checks if it can and folds otherwise.
+func passive(legal []LegalAction) Action {
+	for _, l := range legal {
+		if l.Type == Check {
+			return Action{Type: Check}
+		}
+	}
+	return Action{Type: Fold}
+}
@@ -0,0 +1,84 @@
+package table
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalJSON implements the json.Marshaler interface.  The status is
+// encoded by name, such as "Dealing".
+func (i Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  The status
+// may be given by name or number.
+func (i *Status) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalEnum(b, "status", len(_Status_index)-1, func(v int) string { return Status(v).String() })
+	*i = Status(v)
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  The round is
+// encoded by name, such as "Flop".
+func (i Round) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  The round
+// may be given by name or number.
+func (i *Round) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalEnum(b, "round", len(_Round_index)-1, func(v int) string { return Round(v).String() })
+	*i = Round(v)
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  The variant is
+// encoded by name, such as "TexasHoldem".
+func (i Variant) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  The variant
+// may be given by name or number.
+func (i *Variant) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalEnum(b, "variant", len(_Variant_index)-1, func(v int) string { return Variant(v).String() })
+	*i = Variant(v)
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  The limit is
+// encoded by name, such as "NoLimit".
+func (i Limit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  The limit
+// may be given by name or number.
+func (i *Limit) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalEnum(b, "limit", len(_Limit_index)-1, func(v int) string { return Limit(v).String() })
+	*i = Limit(v)
+	return err
+}
+
+// unmarshalEnum decodes one of the n values of an enum from its number,
+// as they were encoded before they had names, or from its name.
+func unmarshalEnum(b []byte, kind string, n int, name func(int) string) (int, error) {
+	var v int
+	if err := json.Unmarshal(b, &v); err == nil {
+		if v < 0 || v >= n {
+			return 0, fmt.Errorf("table: unknown %s %d", kind, v)
+		}
+		return v, nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return 0, fmt.Errorf("table: %s must be a name or number: %v", kind, err)
+	}
+	for v := 0; v < n; v++ {
+		if name(v) == s {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("table: unknown %s %q", kind, s)
+}
@@ -0,0 +1,95 @@
+package table
+
+// forcedBets posts the bets forced on players before they see their
+// cards, which vary between games.
+type forcedBets interface {
+	// post posts the bets for the hand, records the cards dealt and
+	// returns the seat of the first player to act.
+	post(t *Table) int
+}
+
+// forcedBets returns the forced bets for the game being played.
+func (t *Table) forcedBets() forcedBets {
+	switch {
+	case t.options.Variant == SevenCardStud:
+		return bringIn{}
+	case t.options.AnteOnly:
+		return antesOnly{}
+	}
+	return blinds{}
+}
+
+// blinds has the small and big blind post, and with the AllowStraddle
+// option the player after them straddle, with the player after the last
+// of them first to act.
+type blinds struct{}
+
+func (blinds) post(t *Table) int {
+	if t.sb >= 0 {
+		t.post(t.seats[t.sb], BlindPosted, t.stakes().SmallBlind)
+	}
+	t.post(t.seats[t.bb], BlindPosted, t.stakes().BigBlind)
+	if bb := t.seats[t.bb]; t.options.BigBlindAnte {
+		if chips := bb.postDead(t.stakes().Ante * t.dealtIn()); chips > 0 {
+			t.record(Event{Type: AntePosted, PlayerID: bb.ID, Chips: chips})
+		}
+	}
+	t.recordDeal()
+	t.cost = t.stakes().BigBlind
+	t.bets = 1
+	last := t.bb
+	if t.options.AllowStraddle && !t.headsUp() {
+		last = t.nextSeat(t.bb)
+		straddle := t.seats[last].contribute(2 * t.stakes().BigBlind)
+		t.record(Event{Type: StraddlePosted, PlayerID: t.seats[last].ID, Chips: straddle})
+		// the straddle plays as a third blind setting the minimum raise
+		if straddle > t.cost {
+			t.lastRaise = straddle
+			t.cost = straddle
+			t.bets++
+		}
+	}
+	// players entering the game post the big blind unless it or the
+	// straddle is already theirs, and those who missed blinds also post
+	// a dead small blind out of position
+	for _, seat := range t.seats {
+		if seat.SittingOut || !seat.MustPost {
+			continue
+		}
+		seat.MustPost = false
+		switch seat.Seat {
+		case t.bb, last:
+		case t.sb:
+			t.post(seat, BlindPosted, t.stakes().BigBlind-t.stakes().SmallBlind)
+		default:
+			t.post(seat, BlindPosted, t.stakes().BigBlind)
+			if seat.MissedBlinds > 0 {
+				t.record(Event{Type: BlindPosted, PlayerID: seat.ID, Chips: seat.postDead(t.stakes().SmallBlind)})
+			}
+		}
+		seat.MissedBlinds = 0
+	}
+	return t.nextSeat(last)
+}
+
+// antesOnly posts nothing beyond the antes, so with no blinds the player
+// after the button is first to act.
+type antesOnly struct{}
+
+func (antesOnly) post(t *Table) int {
+	t.recordDeal()
+	for _, seat := range t.seats {
+		seat.MustPost = false
+	}
+	t.cost = 0
+	return t.nextSeat(t.button)
+}
+
+// recordDeal records the hole cards dealt to each player in the hand.
+func (t *Table) recordDeal() {
+	for _, seat := range t.seats {
+		if !seat.SittingOut {
+			t.record(Event{Type: CardsDealt, PlayerID: seat.ID, Cards: seat.Cards})
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/notnil/joker/hand"
+)
+
+// String returns a readable dump of the state for debugging with a row
+// for each seat followed by the result of the last hand if there is one.
+func (s State) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%v %v %v, %v\n", s.Options.Variant, s.Options.Limit, s.Round, s.Status)
+	fmt.Fprintf(b, "board: %s\n", cardsString(s.Cards))
+	fmt.Fprintf(b, "pot: %d, cost: %d", s.Pot, s.Cost)
+	if s.Status == Dealing {
+		fmt.Fprintf(b, ", %s to act", s.Active.ID)
+	}
+	b.WriteString("\n")
+	w := tabwriter.NewWriter(b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "seat\tplayer\tchips\tin pot\tcards\tstatus")
+	for _, seat := range s.Seats {
+		button := ""
+		if seat.Seat == s.Button && !s.DeadButton {
+			button = " D"
+		}
+		status := seat.status()
+		if s.Status == Dealing && seat.ID == s.Active.ID {
+			status = "to act"
+		}
+		fmt.Fprintf(w, "%d%s\t%s\t%d\t%d\t%s\t%s\n", seat.Seat, button, seat.ID, seat.Chips, seat.ChipsInPot, cardsString(seat.Cards), status)
+	}
+	w.Flush()
+	if s.Result != nil {
+		fmt.Fprintf(b, "last hand:\n%v", s.Result)
+	}
+	return b.String()
+}
+
+// String returns a readable dump of the result for debugging with a row
+// for each pot.
+func (r Result) String() string {
+	b := &strings.Builder{}
+	if len(r.Boards) > 1 {
+		for i, board := range r.Boards {
+			fmt.Fprintf(b, "board %d: %s\n", i, cardsString(board))
+		}
+	} else {
+		fmt.Fprintf(b, "board: %s\n", cardsString(r.Board))
+	}
+	w := tabwriter.NewWriter(b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "pot\tchips\twinners\tlow winners\thand")
+	for i, pot := range r.Pots {
+		desc := "-"
+		if pot.Hand != nil {
+			desc = pot.Hand.String()
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\n", i, pot.Chips, idsString(pot.Winners), idsString(pot.LowWinners), desc)
+	}
+	w.Flush()
+	if r.Raked > 0 {
+		fmt.Fprintf(b, "raked: %d\n", r.Raked)
+	}
+	if r.Returned > 0 {
+		fmt.Fprintf(b, "returned: %d to %s\n", r.Returned, r.ReturnedTo)
+	}
+	fmt.Fprintf(b, "winners: %s\n", idsString(r.Winners))
+	return b.String()
+}
+
+// status describes whether the player is still in the hand.
+func (p Player) status() string {
+	switch {
+	case p.SittingOut:
+		return "sitting out"
+	case p.Folded:
+		return "folded"
+	case p.AllIn:
+		return "all in"
+	}
+	return "-"
+}
+
+func cardsString(cards []hand.Card) string {
+	if len(cards) == 0 {
+		return "-"
+	}
+	s := []string{}
+	for _, c := range cards {
+		s = append(s, c.String())
+	}
+	return strings.Join(s, " ")
+}
+
+func idsString(ids []string) string {
+	if len(ids) == 0 {
+		return "-"
+	}
+	return strings.Join(ids, ", ")
+}
@@ -0,0 +1,292 @@
+package table
+
+import "github.com/notnil/joker/hand"
+
+// EventType is the kind of event recorded in a hand's history.
+type EventType int
+
+const (
+	// AntePosted is recorded when a player posts an ante.
+	AntePosted EventType = iota
+
+	// BlindPosted is recorded when a player posts a small or big blind.
+	BlindPosted
+
+	// StraddlePosted is recorded when a player posts a straddle.
+	StraddlePosted
+
+	// BringInPosted is recorded when the player with the lowest up card
+	// posts the bring in in seven card stud.
+	BringInPosted
+
+	// CardsDealt is recorded when a player is dealt their hole cards, or
+	// in seven card stud any card.
+	CardsDealt
+
+	// ActionTaken is recorded when a player acts.
+	ActionTaken
+
+	// CardBurned is recorded when a card is burned before a street of the
+	// board is dealt.
+	CardBurned
+
+	// BoardDealt is recorded when community cards are revealed.
+	BoardDealt
+
+	// PotAwarded is recorded when a player wins chips from a pot.
+	PotAwarded
+
+	// HandStarted is recorded first in each hand, with the player on the
+	// button unless it is dead.
+	HandStarted
+
+	// Showdown is recorded for each player who shows their cards at
+	// showdown, in the order they show, before the pots are awarded.
+	Showdown
+
+	// BetReturned is recorded when the part of a bet or raise no one
+	// called goes back to the player who made it, before the pots are
+	// awarded.
+	BetReturned
+)
+
+// Event is an entry in a hand's history.  Chips is the amount a player
+// put into or won from the pot and Cards are the cards dealt, if any.
+type Event struct {
+	Type     EventType
+	PlayerID string
+	Action   ActionType
+	Chips    int
+	Round    Round
+	Cards    []hand.Card
+}
+
+// History returns the events of the hand in progress in the order they
+// occurred.
+func (t *Table) History() []Event {
+	t.mu.Lock()
+	defer t.unlock()
+	return append([]Event(nil), t.events...)
+}
+
+// LastHistory returns the events of the most recently completed hand.
+func (t *Table) LastHistory() []Event {
+	t.mu.Lock()
+	defer t.unlock()
+	return append([]Event(nil), t.lastEvents...)
+}
+
+// PlayerStats is what a player put in the pot in a hand, from which HUD
+// stats such as how often they voluntarily put chips in the pot or raise
+// before the flop can be computed.
+type PlayerStats struct {
+	// Forced is the chips posted as antes, blinds, straddles or the
+	// bring in
+	Forced int
+	// Rounds is the chips put in by calling, betting or raising in each
+	// round, less any bet returned uncalled
+	Rounds map[Round]int
+	// Voluntary is set if the player put chips in before the flop
+	// without being forced to
+	Voluntary bool
+	// PreFlopRaise is set if the player bet or raised before the flop,
+	// including by going all in for more than the call
+	PreFlopRaise bool
+}
+
+// PlayerStats returns what the player has put in the pot in the hand in
+// progress.
+func (t *Table) PlayerStats(id string) PlayerStats {
+	t.mu.Lock()
+	defer t.unlock()
+	return playerStats(t.events, id)
+}
+
+// LastPlayerStats returns what the player put in the pot in the most
+// recently completed hand.
+func (t *Table) LastPlayerStats(id string) PlayerStats {
+	t.mu.Lock()
+	defer t.unlock()
+	return playerStats(t.lastEvents, id)
+}
+
+func playerStats(events []Event, id string) PlayerStats {
+	stats := PlayerStats{Rounds: map[Round]int{}}
+	// live is what each player has put in toward calls and last the last
+	// round the player put chips in, from which any uncalled bet came
+	live := map[string]int{}
+	last := PreFlop
+	for _, e := range events {
+		switch e.Type {
+		case AntePosted, BlindPosted, StraddlePosted, BringInPosted:
+			if e.PlayerID == id {
+				stats.Forced += e.Chips
+			}
+			if e.Type != AntePosted {
+				live[e.PlayerID] += e.Chips
+			}
+		case ActionTaken:
+			cost := 0
+			for _, chips := range live {
+				cost = max(cost, chips)
+			}
+			live[e.PlayerID] += e.Chips
+			if e.PlayerID != id || e.Chips == 0 {
+				continue
+			}
+			stats.Rounds[e.Round] += e.Chips
+			last = e.Round
+			if e.Round == PreFlop {
+				stats.Voluntary = true
+				raised := e.Action == Bet || e.Action == Raise
+				stats.PreFlopRaise = stats.PreFlopRaise || raised || e.Action == AllIn && live[id] > cost
+			}
+		case BetReturned:
+			if e.PlayerID == id {
+				stats.Rounds[last] -= e.Chips
+			}
+		}
+	}
+	return stats
+}
+
+// ViewCard is a card as seen by one player.  Hidden cards are face down
+// to them and Card is unset.
+type ViewCard struct {
+	Card   hand.Card
+	Hidden bool
+}
+
+// PlayerView is a player's cards in a hand as seen by another player.
+type PlayerView struct {
+	ID    string
+	Cards []ViewCard
+}
+
+// View returns the cards of every player dealt into the hand in progress
+// as seen by the viewer, who sees their own cards and the up cards in
+// seven card stud.  An empty viewer sees the hand as a spectator.
+func (t *Table) View(viewer string) []PlayerView {
+	t.mu.Lock()
+	defer t.unlock()
+	return playerViews(t.events, t.options.Variant, viewer, nil)
+}
+
+// LastView returns the cards of every player dealt into the most recently
+// completed hand as seen by the viewer, who also sees the cards shown
+// when it ended.
+func (t *Table) LastView(viewer string) []PlayerView {
+	t.mu.Lock()
+	defer t.unlock()
+	var shown map[string][]hand.Card
+	if t.result != nil {
+		shown = t.result.Shown
+	}
+	return playerViews(t.lastEvents, t.options.Variant, viewer, shown)
+}
+
+func playerViews(events []Event, v Variant, viewer string, shown map[string][]hand.Card) []PlayerView {
+	views := []PlayerView{}
+	index := map[string]int{}
+	for _, e := range events {
+		if e.Type != CardsDealt {
+			continue
+		}
+		i, ok := index[e.PlayerID]
+		if !ok {
+			i = len(views)
+			index[e.PlayerID] = i
+			views = append(views, PlayerView{ID: e.PlayerID})
+		}
+		for j, c := range e.Cards {
+			// stud deals two down cards and one up card to start, then up
+			// cards until the last card which is down
+			up := v == SevenCardStud && (e.Round == PreFlop && j >= 2 || e.Round > PreFlop && e.Round < SeventhStreet)
+			card := ViewCard{Card: c}
+			if !up && e.PlayerID != viewer && !containsCard(shown[e.PlayerID], c) {
+				card = ViewCard{Hidden: true}
+			}
+			views[i].Cards = append(views[i].Cards, card)
+		}
+	}
+	return views
+}
+
+// DeckFingerprint returns the fingerprint of the deck for the hand in
+// progress taken before any cards were dealt from it.  Comparing it with
+// the fingerprint of the dealer's deck proves the deal wasn't changed.
+func (t *Table) DeckFingerprint() string {
+	t.mu.Lock()
+	defer t.unlock()
+	return t.fingerprint
+}
+
+// committer is a dealer that commits to the shuffle of each deck, such as
+// hand.CommitDealer.
+type committer interface {
+	RevealShuffle() (string, []hand.Card)
+}
+
+// CommitShuffle returns the commitment to the shuffle of the deck for the
+// hand in progress if the dealer commits to its shuffles and an empty
+// string otherwise.
+func (t *Table) CommitShuffle() string {
+	t.mu.Lock()
+	defer t.unlock()
+	if t.shuffle == nil {
+		return ""
+	}
+	return t.shuffle.Commitment()
+}
+
+// RevealShuffle returns the seed and order of the deck for the most
+// recently completed hand so it can be checked against the commitment
+// published before it was dealt.  The shuffle for the hand in progress is
+// never revealed.
+func (t *Table) RevealShuffle() (string, []hand.Card) {
+	t.mu.Lock()
+	defer t.unlock()
+	if t.lastShuffle == nil {
+		return "", nil
+	}
+	return t.lastShuffle.Seed, append([]hand.Card(nil), t.lastShuffle.Deck...)
+}
+
+// OnEvent registers a handler to be called with each event as it is
+// recorded, such as to tell the server when to push changes to players
+// instead of polling.  Events hold every player's hole cards and the burn
+// cards, so they mustn't be passed on to players as they are: send each
+// player their StateFor or View instead.  Handlers are called in the
+// order they were registered once the method recording the events
+// releases the table, so they may use the table themselves.  Handlers
+// aren't included in snapshots.
+func (t *Table) OnEvent(handler func(Event)) {
+	t.mu.Lock()
+	defer t.unlock()
+	t.handlers = append(t.handlers, handler)
+}
+
+func (t *Table) record(e Event) {
+	e.Round = t.round
+	t.events = append(t.events, e)
+	if len(t.handlers) > 0 {
+		t.unpublished = append(t.unpublished, e)
+	}
+}
+
+// unlock releases the table and then logs the lines and calls the
+// handlers with the events recorded while it was held.
+func (t *Table) unlock() {
+	events, handlers := t.unpublished, t.handlers
+	lines, logger := t.unlogged, t.options.Logger
+	t.unpublished, t.unlogged = nil, nil
+	t.mu.Unlock()
+	for _, line := range lines {
+		logger.Printf("%s", line)
+	}
+	for _, e := range events {
+		for _, handler := range handlers {
+			handler(e)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package table
+
+// betLimit bounds the bets and raises allowed, which vary between limits.
+type betLimit interface {
+	// minRaise returns the smallest bet or raise allowed.
+	minRaise(t *Table) int
+	// maxRaise returns the most the limit allows the active player to bet
+	// or raise after calling, which may be more than their stack.
+	maxRaise(t *Table) int
+	// maxRaises returns the most raises allowed in a round after the
+	// first bet, or zero if there is no cap.
+	maxRaises() int
+}
+
+// betLimit returns the limit for the game being played.
+func (t *Table) betLimit() betLimit {
+	switch t.options.Limit {
+	case PotLimit:
+		return potLimit{}
+	case FixedLimit:
+		return fixedLimit{}
+	}
+	return noLimit{}
+}
+
+// noLimit caps bets and raises only at the player's stack.  A raise must
+// be at least the big blind or the last full raise of the round.
+type noLimit struct{}
+
+func (noLimit) minRaise(t *Table) int {
+	return max(t.stakes().BigBlind, t.lastRaise)
+}
+
+func (noLimit) maxRaise(t *Table) int {
+	return t.active.Chips - t.owed()
+}
+
+func (noLimit) maxRaises() int {
+	return 0
+}
+
+// potLimit caps bets and raises at the size of the pot after calling.
+type potLimit struct {
+	noLimit
+}
+
+func (potLimit) maxRaise(t *Table) int {
+	return t.maxPotRaise()
+}
+
+// fixedLimit has every bet and raise be the small bet, the big blind,
+// before the flop and on the flop, and the big bet, twice the big blind,
+// from the turn on.  The first bet completes the bring in in seven card
+// stud.  Betting is capped at a bet and three raises each round unless
+// the MaxRaisesPerRound option says otherwise.
+type fixedLimit struct{}
+
+func (fixedLimit) minRaise(t *Table) int {
+	bet := t.stakes().BigBlind
+	if t.round >= Turn {
+		bet *= 2
+	}
+	// only the bring in is short of a bet, and t.cost counts the chips
+	// bet in earlier rounds too
+	if t.round == PreFlop && t.bets == 0 && t.cost > 0 && t.cost < bet {
+		return bet - t.cost
+	}
+	return bet
+}
+
+func (l fixedLimit) maxRaise(t *Table) int {
+	return l.minRaise(t)
+}
+
+func (fixedLimit) maxRaises() int {
+	return 3
+}
+
+// maxRaises returns the most raises allowed in a round after the first
+// bet, which is the MaxRaisesPerRound option if set and the limit's cap
+// otherwise, or zero if there is no cap.
+func (t *Table) maxRaises() int {
+	if n := t.options.MaxRaisesPerRound; n > 0 {
+		return n
+	}
+	return t.betLimit().maxRaises()
+}
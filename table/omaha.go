@@ -0,0 +1,94 @@
+package table
+
+import (
+	"sort"
+
+	"github.com/notnil/joker/hand"
+	"github.com/notnil/joker/util"
+)
+
+// bestOmahaHand returns the best hand formed from exactly two of the hole
+// cards and three of the board cards.  If the board has fewer than three
+// cards all of them are used.
+func bestOmahaHand(hole, board []hand.Card) *hand.Hand {
+	k := 3
+	if len(board) < k {
+		k = len(board)
+	}
+	boardCombos := util.Combinations(len(board), k)
+	if k == 0 {
+		boardCombos = [][]int{{}}
+	}
+	hands := []*hand.Hand{}
+	for _, hc := range util.Combinations(len(hole), 2) {
+		for _, bc := range boardCombos {
+			cards := []hand.Card{}
+			for _, i := range hc {
+				cards = append(cards, hole[i])
+			}
+			for _, i := range bc {
+				cards = append(cards, board[i])
+			}
+			hands = append(hands, hand.New(cards))
+		}
+	}
+	if len(hands) == 0 {
+		return hand.New(append(append([]hand.Card{}, hole...), board...))
+	}
+	return hand.Sort(hand.SortingHigh, hand.DESC, hands...)[0]
+}
+
+// lowHand is a qualifying low of five distinct ranks of eight or lower in
+// descending order with aces counted as one.
+type lowHand []int
+
+// bestOmahaLow returns the best eight or better low formed from exactly two
+// of the hole cards and three of the board cards or nil if there isn't one.
+func bestOmahaLow(hole, board []hand.Card) lowHand {
+	var best lowHand
+	for _, hc := range util.Combinations(len(hole), 2) {
+		for _, bc := range util.Combinations(len(board), 3) {
+			cards := []hand.Card{}
+			for _, i := range hc {
+				cards = append(cards, hole[i])
+			}
+			for _, i := range bc {
+				cards = append(cards, board[i])
+			}
+			low := qualifyingLow(cards)
+			if low != nil && (best == nil || compareLows(low, best) < 0) {
+				best = low
+			}
+		}
+	}
+	return best
+}
+
+// qualifyingLow returns the cards as a low or nil if they are paired or
+// any is higher than an eight.
+func qualifyingLow(cards []hand.Card) lowHand {
+	low := lowHand{}
+	for _, c := range cards {
+		v := int(c.Rank()) + 2
+		if c.Rank() == hand.Ace {
+			v = 1
+		}
+		if v > 8 || contains(low, v) {
+			return nil
+		}
+		low = append(low, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(low)))
+	return low
+}
+
+// compareLows returns a negative value if a is the better low, a positive
+// value if b is, and zero if they are equal.
+func compareLows(a, b lowHand) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
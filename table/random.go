@@ -0,0 +1,38 @@
+package table
+
+import "math/rand"
+
+// PlayRandom plays out the game choosing legal actions at random from the
+// random source until the table is Waiting or Broken.  It returns the state
+// after each action starting with the current state.  The same source
+// and table always play the same game, which makes it useful for fuzzing.
+func (t *Table) PlayRandom(r *rand.Rand) []State {
+	t.mu.Lock()
+	defer t.unlock()
+	states := []State{t.state()}
+	for t.status == Dealing {
+		if err := t.act(t.randomAction(r)); err != nil {
+			break
+		}
+		states = append(states, t.state())
+	}
+	return states
+}
+
+// randomAction returns a random legal action for the active player, with
+// a random amount within the limits for bets and raises.
+func (t *Table) randomAction(r *rand.Rand) Action {
+	minRaise, maxRaise := t.minRaise(), t.maxRaise()
+	legal := []ActionType{}
+	for _, a := range t.legalActions() {
+		if (a == Bet || a == Raise) && maxRaise < minRaise {
+			continue
+		}
+		legal = append(legal, a)
+	}
+	a := Action{Type: legal[r.Intn(len(legal))]}
+	if a.Type == Bet || a.Type == Raise {
+		a.Chips = minRaise + r.Intn(maxRaise-minRaise+1)
+	}
+	return a
+}
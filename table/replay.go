@@ -0,0 +1,28 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/notnil/joker/hand"
+)
+
+// Replay seats the players at a new table and takes the actions in turn,
+// such as to reproduce a hand from its history.  It returns the state
+// after each action.  If the table can't be created or an action isn't
+// legal when its turn comes, Replay returns the states up to that point
+// with an error saying which action failed.
+func Replay(dealer hand.Dealer, opts Options, playerIDs []string, actions []Action) ([]State, error) {
+	t, err := NewWithError(dealer, opts, playerIDs)
+	if err != nil {
+		return nil, err
+	}
+	states := []State{}
+	for i, a := range actions {
+		if err := t.Act(a); err != nil {
+			return states, fmt.Errorf("table: action %d (%v) failed: %s", i, a.Type, strings.TrimPrefix(err.Error(), "table: "))
+		}
+		states = append(states, t.State())
+	}
+	return states, nil
+}
@@ -0,0 +1,185 @@
+package table
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/notnil/joker/hand"
+)
+
+// Snapshot is everything needed to restore a table, including the order
+// of the cards left in the deck, so an interrupted hand can be resumed
+// exactly where it left off.  It can be encoded as JSON.
+type Snapshot struct {
+	Options Options
+	Seats   []Player
+	Deck    *hand.Deck
+	// Fingerprint is the fingerprint of the deck before the hand was dealt
+	Fingerprint string
+	// Shuffle and LastShuffle are the dealer's shuffles for this hand and
+	// the last if it commits to them
+	Shuffle     *hand.Shuffle
+	LastShuffle *hand.Shuffle
+	Cards       []hand.Card
+	// Active is the seat of the player to act or -1 if no one is
+	Active     int
+	Status     Status
+	Round      Round
+	Button     int
+	SB         int
+	BB         int
+	DeadButton bool
+	// NextButton is the ID of the player the button was moved to for the
+	// next hand or empty if it wasn't
+	NextButton string
+	// Aggressor is the ID of the last player to bet or raise this round
+	// or empty if no one has
+	Aggressor  string
+	Cost       int
+	LastRaise  int
+	Bets       int
+	Events     []Event
+	LastEvents []Event
+	Result     *Result
+	Level      int
+	Hands      int
+	Err        string
+	// Chips is the number of chips that should be on the table
+	Chips int
+}
+
+// Snapshot returns a copy of the table's current state.
+func (t *Table) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.unlock()
+	return t.snapshot()
+}
+
+func (t *Table) snapshot() Snapshot {
+	seats := []Player{}
+	for _, seat := range t.seats {
+		p := *seat
+		p.Cards = append([]hand.Card(nil), seat.Cards...)
+		p.UpCards = append([]hand.Card(nil), seat.UpCards...)
+		seats = append(seats, p)
+	}
+	var deck *hand.Deck
+	if t.deck != nil {
+		deck = &hand.Deck{Cards: append([]hand.Card{}, t.deck.Cards...)}
+	}
+	active := -1
+	if t.active != nil {
+		active = t.active.Seat
+	}
+	nextButton := ""
+	if t.nextButton != nil {
+		nextButton = t.nextButton.ID
+	}
+	aggressor := ""
+	if t.aggressor != nil {
+		aggressor = t.aggressor.ID
+	}
+	err := ""
+	if t.err != nil {
+		err = t.err.Error()
+	}
+	return Snapshot{
+		Options:     t.options,
+		Seats:       seats,
+		Deck:        deck,
+		Fingerprint: t.fingerprint,
+		Shuffle:     t.shuffle,
+		LastShuffle: t.lastShuffle,
+		Cards:       append([]hand.Card(nil), t.cards...),
+		Active:      active,
+		Status:      t.status,
+		Round:       t.round,
+		Button:      t.button,
+		SB:          t.sb,
+		BB:          t.bb,
+		DeadButton:  t.deadButton,
+		NextButton:  nextButton,
+		Aggressor:   aggressor,
+		Cost:        t.cost,
+		LastRaise:   t.lastRaise,
+		Bets:        t.bets,
+		Events:      append([]Event(nil), t.events...),
+		LastEvents:  append([]Event(nil), t.lastEvents...),
+		Result:      t.result,
+		Level:       t.level,
+		Hands:       t.hands,
+		Err:         err,
+		Chips:       t.chips,
+	}
+}
+
+// Restore returns a table in the state captured by the snapshot.  The
+// hand in progress is finished with the snapshot's deck and later hands
+// are dealt by the dealer.  The active player's clock starts over.
+func Restore(dealer hand.Dealer, s Snapshot) *Table {
+	t := &Table{
+		options:     s.Options,
+		dealer:      dealer,
+		fingerprint: s.Fingerprint,
+		shuffle:     s.Shuffle,
+		lastShuffle: s.LastShuffle,
+		cards:       append([]hand.Card(nil), s.Cards...),
+		status:      s.Status,
+		round:       s.Round,
+		button:      s.Button,
+		sb:          s.SB,
+		bb:          s.BB,
+		deadButton:  s.DeadButton,
+		cost:        s.Cost,
+		lastRaise:   s.LastRaise,
+		bets:        s.Bets,
+		events:      append([]Event(nil), s.Events...),
+		lastEvents:  append([]Event(nil), s.LastEvents...),
+		result:      s.Result,
+		level:       s.Level,
+		hands:       s.Hands,
+		chips:       s.Chips,
+		activeSince: time.Now(),
+	}
+	for _, seat := range s.Seats {
+		p := seat
+		p.Cards = append([]hand.Card(nil), seat.Cards...)
+		p.UpCards = append([]hand.Card(nil), seat.UpCards...)
+		t.seats = append(t.seats, &p)
+	}
+	if s.Deck != nil {
+		t.deck = &hand.Deck{Cards: append([]hand.Card{}, s.Deck.Cards...)}
+	}
+	if s.Active >= 0 && s.Active < len(t.seats) {
+		t.active = t.seats[s.Active]
+	}
+	if s.NextButton != "" {
+		t.nextButton = t.player(s.NextButton)
+	}
+	if s.Aggressor != "" {
+		t.aggressor = t.player(s.Aggressor)
+	}
+	if s.Err != "" {
+		t.err = errors.New(s.Err)
+	}
+	return t
+}
+
+// Clone returns a copy of the table that can be acted on without
+// affecting the table, such as to try out actions.  The hand in progress
+// is copied in full but later hands are dealt by a dealer of the clone's
+// own with a new random source, so they differ from the table's and
+// drawing them leaves the table's dealer alone.  The clone has no Logger
+// or event handlers, so actions taken on it are neither logged nor
+// published as if taken at the table.
+func (t *Table) Clone() *Table {
+	t.mu.Lock()
+	s, activeSince := t.snapshot(), t.activeSince
+	t.unlock()
+	s.Options.Logger = nil
+	dealer := hand.NewDealer(rand.New(rand.NewSource(time.Now().UnixNano())))
+	c := Restore(dealer, s)
+	c.activeSince = activeSince
+	return c
+}
@@ -0,0 +1,88 @@
+package table
+
+import "github.com/notnil/joker/hand"
+
+// bringIn has the player with the lowest up card on third street post
+// the bring in, with the player after them first to act.
+type bringIn struct{}
+
+func (bringIn) post(t *Table) int {
+	for _, seat := range t.seats {
+		if !seat.SittingOut {
+			seat.UpCards = append([]hand.Card(nil), seat.Cards[2:]...)
+		}
+	}
+	t.recordDeal()
+	p := t.seats[t.lowestUpCard()]
+	t.post(p, BringInPosted, t.stakes().BringIn)
+	t.cost = t.stakes().BringIn
+	return t.nextSeat(p.Seat)
+}
+
+// dealStreet deals another card to every player left in the hand.
+func (t *Table) dealStreet(up bool) {
+	for _, seat := range t.seats {
+		if seat.SittingOut || seat.Folded {
+			continue
+		}
+		card := t.deck.Pop()
+		seat.Cards = append(seat.Cards, card)
+		if up {
+			seat.UpCards = append(seat.UpCards, card)
+		}
+		t.record(Event{Type: CardsDealt, PlayerID: seat.ID, Cards: []hand.Card{card}})
+	}
+}
+
+// lowestUpCard returns the seat with the lowest up card, with suits ranked
+// from clubs, the lowest, through diamonds and hearts to spades.
+func (t *Table) lowestUpCard() int {
+	low := -1
+	for _, seat := range t.seats {
+		if seat.SittingOut {
+			continue
+		}
+		if low == -1 || lowerCard(seat.UpCards[0], t.seats[low].UpCards[0]) {
+			low = seat.Seat
+		}
+	}
+	return low
+}
+
+func lowerCard(a, b hand.Card) bool {
+	if a.Rank() != b.Rank() {
+		return a.Rank() < b.Rank()
+	}
+	// Spades through Clubs ascend so the higher suit is the lower card
+	return a.Suit() > b.Suit()
+}
+
+// bestShowing returns the seat of the player left in the hand with the
+// best up cards, who acts first after third street.  Players all in are
+// passed over unless everyone is.  Ties go to the lowest seat.
+func (t *Table) bestShowing() int {
+	best := -1
+	var bestHand *hand.Hand
+	betting := false
+	for _, seat := range t.seats {
+		betting = betting || !seat.SittingOut && !seat.Folded && !seat.AllIn
+	}
+	for _, seat := range t.seats {
+		if seat.SittingOut || seat.Folded || betting && seat.AllIn {
+			continue
+		}
+		h := hand.New(seat.UpCards)
+		if bestHand == nil || h.CompareTo(bestHand) > 0 {
+			best, bestHand = seat.Seat, h
+		}
+	}
+	return best
+}
+
+// lastRound returns the variant's last betting round.
+func (t *Table) lastRound() Round {
+	if t.options.Variant == SevenCardStud {
+		return SeventhStreet
+	}
+	return River
+}
@@ -2,7 +2,11 @@ package table
 
 import (
 	"errors"
+	"fmt"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/notnil/joker/hand"
 )
@@ -10,8 +14,14 @@ import (
 type Status int
 
 const (
+	// Broken is the status of a table that can't deal a hand, see Err for
+	// the reason
 	Broken Status = iota
 	Dealing
+	// Waiting is the status between hands while fewer than two players
+	// can be dealt in, such as once all but one has run out of chips.
+	// Dealing resumes when a player joins, buys in, tops up or sits in.
+	Waiting
 )
 
 type Round int
@@ -21,6 +31,9 @@ const (
 	Flop
 	Turn
 	River
+	// SeventhStreet is the last round of seven card stud, which plays
+	// PreFlop through River as third through sixth street
+	SeventhStreet
 )
 
 type Variant int
@@ -28,6 +41,16 @@ type Variant int
 const (
 	TexasHoldem Variant = iota
 	OmahaHi
+	// OmahaHiLo splits each pot between the best high hand and the best
+	// eight or better low
+	OmahaHiLo
+	// ShortDeck is hold'em with the twos through fives removed from the
+	// deck, where a flush beats a full house and A-6-7-8-9 is a straight
+	ShortDeck
+	// SevenCardStud deals each player their own seven cards, four of them
+	// face up, with no board.  The lowest up card posts a bring in instead
+	// of blinds.
+	SevenCardStud
 )
 
 type Limit int
@@ -35,6 +58,7 @@ type Limit int
 const (
 	NoLimit Limit = iota
 	PotLimit
+	FixedLimit
 )
 
 type Options struct {
@@ -42,30 +66,252 @@ type Options struct {
 	Variant Variant
 	Stakes  Stakes
 	Limit   Limit
+	Rake    Rake
+	// HoleCards is the number of cards dealt to each player.  If zero the
+	// variant's default is used.
+	HoleCards int
+	// MaxSeats is the most players that can be seated.  If zero the
+	// variant's default is used: ten for Hold'em, nine for Omaha, six for
+	// short deck and seven for stud.
+	MaxSeats int
+	// PostToEnter has players added to the table post a big blind on
+	// their first hand unless they are in the big blind.
+	PostToEnter bool
+	// PostMissedBlinds has players returning after the big blind passed
+	// them while sitting out wait for the big blind or post to come back
+	// in with PostMissedBlind.
+	PostMissedBlinds bool
+	// WaitForBigBlind has players added to the table sit out until the
+	// big blind reaches them unless they PostBlind to be dealt in sooner.
+	WaitForBigBlind bool
+	// ActionTimeout is how long the active player has to act before
+	// CheckTimeout acts for them.  If zero there is no time limit.
+	ActionTimeout time.Duration
+	// AllowStraddle has the player after the big blind post a straddle of
+	// twice the big blind and act last before the flop.  There is no
+	// straddle heads up and three handed the button straddles.
+	AllowStraddle bool
+	// MaxRaisesPerRound caps the raises after the first bet of each
+	// round, after which players may only call or fold.  If zero there is
+	// no cap except under fixed limit, which allows three.
+	MaxRaisesPerRound int
+	// AnteOnly collects an ante from every player and posts no blinds.
+	// The big blind is only the minimum bet and the player after the
+	// button acts first in every round.
+	AnteOnly bool
+	// BigBlindAnte has the big blind post the ante for every player dealt
+	// in after their blind instead of each player posting their own.
+	BigBlindAnte bool
+	// RunItTwice deals the rest of the board twice once every player left
+	// in the hand is all in, splitting each pot between the two runouts.
+	RunItTwice bool
+	// AutoMuck mucks the cards of players at showdown whose hand is beaten
+	// by one already shown, unless they need to show to win a pot.
+	AutoMuck bool
+	// BlindSchedule replaces Stakes with escalating levels for tournament
+	// play.  The level advances every HandsPerLevel hands if it is set, or
+	// when AdvanceBlindLevel is called.
+	BlindSchedule []Stakes
+	HandsPerLevel int
+	// Evaluator decides the best high hand at showdown in place of the
+	// variant's usual rankings if set.  Hands are still described by the
+	// usual rankings.  It isn't included in snapshots.
+	Evaluator Evaluator `json:"-"`
+	// MaxStack caps the chips a player may bring to the table with a
+	// buyin or top up.  Chips won at the table aren't capped.  If zero
+	// there is no cap.
+	MaxStack int
+	// Logger is sent a line for each action taken if set, once the method
+	// taking it releases the table.  Nothing is logged by default.  It
+	// isn't included in snapshots.
+	Logger Logger `json:"-"`
 }
 
+// Validate returns an error describing the first problem with the
+// options that would keep a table from dealing.
+func (opts Options) Validate() error {
+	levels := opts.BlindSchedule
+	if len(levels) == 0 {
+		levels = []Stakes{opts.Stakes}
+	}
+	for _, stakes := range levels {
+		if stakes.Ante < 0 {
+			return fmt.Errorf("table: ante of %d can't be negative", stakes.Ante)
+		}
+		if opts.Variant == SevenCardStud {
+			if stakes.BringIn <= 0 || stakes.BringIn > stakes.BigBlind {
+				return fmt.Errorf("table: bring in of %d must be positive and at most the big blind of %d", stakes.BringIn, stakes.BigBlind)
+			}
+			continue
+		}
+		if opts.AnteOnly {
+			if stakes.Ante <= 0 {
+				return fmt.Errorf("table: ante of %d must be positive without blinds", stakes.Ante)
+			}
+			if stakes.BigBlind <= 0 {
+				return fmt.Errorf("table: big blind of %d must be positive", stakes.BigBlind)
+			}
+			continue
+		}
+		if stakes.SmallBlind <= 0 {
+			return fmt.Errorf("table: small blind of %d must be positive", stakes.SmallBlind)
+		}
+		if stakes.BigBlind < stakes.SmallBlind {
+			return fmt.Errorf("table: big blind of %d is less than the small blind of %d", stakes.BigBlind, stakes.SmallBlind)
+		}
+	}
+	if bb := levels[0].BigBlind; opts.Buyin < bb {
+		return fmt.Errorf("table: buyin of %d is less than the big blind of %d", opts.Buyin, bb)
+	}
+	if opts.MaxStack > 0 && opts.Buyin > opts.MaxStack {
+		return fmt.Errorf("table: buyin of %d is more than the max stack of %d", opts.Buyin, opts.MaxStack)
+	}
+	if opts.Rake.Percent < 0 || opts.Rake.Percent > 100 {
+		return fmt.Errorf("table: rake of %v%% must be between 0 and 100", opts.Rake.Percent)
+	}
+	if opts.MaxRaisesPerRound < 0 {
+		return fmt.Errorf("table: max raises per round of %d can't be negative", opts.MaxRaisesPerRound)
+	}
+	if opts.MaxSeats != 0 && opts.MaxSeats < 2 {
+		return fmt.Errorf("table: max seats of %d must be at least two", opts.MaxSeats)
+	}
+	if opts.BigBlindAnte && (opts.AnteOnly || opts.Variant == SevenCardStud) {
+		return errors.New("table: a big blind ante needs a big blind to post it")
+	}
+	if opts.WaitForBigBlind && (opts.AnteOnly || opts.Variant == SevenCardStud) {
+		return errors.New("table: players can't wait for a big blind that isn't posted")
+	}
+	if opts.RunItTwice && opts.Variant == SevenCardStud {
+		return errors.New("table: seven card stud has no board to run twice")
+	}
+	if !validHoleCards(opts) {
+		return fmt.Errorf("table: %d hole cards can't be used to play %v", holeCards(opts), opts.Variant)
+	}
+	return nil
+}
+
+// Evaluator compares two players' hands, each of which is the player's
+// hole cards followed by the board.  Compare returns a positive value if
+// the first hand wins, a negative value if the second does and zero if
+// they tie.
+type Evaluator interface {
+	Compare(a, b []hand.Card) int
+}
+
+// Logger receives the table's log messages.  A *log.Logger can be used.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Rake is the percentage of each pot taken by the house, up to Cap chips
+// per hand.  A Cap of zero is uncapped.  No rake is taken from hands that
+// end before the flop.
+type Rake struct {
+	Percent float64
+	Cap     int
+}
+
+// Stakes are the forced bets.  In seven card stud the bring in is posted
+// instead of blinds and the big blind is only the minimum bet.
 type Stakes struct {
 	BigBlind   int
 	SmallBlind int
 	Ante       int
+	BringIn    int
 }
 
+// Table is safe for concurrent use.  Exported methods acquire mu before
+// touching table state and release it with unlock, and unexported helpers
+// assume it is already held, so a method holding the lock must only call
+// unexported helpers.
 type Table struct {
+	mu      sync.Mutex
 	options Options
-	seats   []*Player
-	dealer  hand.Dealer
-	deck    *hand.Deck
-	cards   []hand.Card
-	active  *Player
-	status  Status
-	round   Round
-	button  int
-	cost    int
+	// seats are never nil as players leaving are removed between hands
+	// and the seats after them renumbered
+	seats  []*Player
+	dealer hand.Dealer
+	deck   *hand.Deck
+	// fingerprint is the deck's fingerprint before the hand was dealt
+	fingerprint string
+	// shuffle is the dealer's shuffle of the deck if it commits to them
+	// and lastShuffle that of the last hand
+	shuffle     *hand.Shuffle
+	lastShuffle *hand.Shuffle
+	cards       []hand.Card
+	active      *Player
+	status      Status
+	round       Round
+	button      int
+	cost        int
+	// sb and bb are the seats of the blinds, with sb -1 if the small
+	// blind is dead, and deadButton is set if no player has the button
+	sb         int
+	bb         int
+	deadButton bool
+	// nextButton is the player SetButton moved the button to next hand
+	nextButton *Player
+	// lastRaise is the size of the last full bet or raise this round and
+	// bets is the number of them, counting the big blind, so there have
+	// been one fewer raises
+	lastRaise int
+	bets      int
+	// aggressor is the last player to bet or raise this round
+	aggressor  *Player
+	events     []Event
+	lastEvents []Event
+	result     *Result
+	// activeSince is when the active player's clock started
+	activeSince time.Time
+	// err is why the table is Broken
+	err error
+	// chips is the number of chips that should be on the table
+	chips int
+	// level is the index into the blind schedule and hands the number of
+	// hands dealt at that level
+	level int
+	hands int
+	// handlers are called with the events in unpublished and the Logger
+	// with the lines in unlogged once the lock is released
+	handlers    []func(Event)
+	unpublished []Event
+	unlogged    []string
 }
 
+// New returns a table dealing its first hand to the players.  The table
+// is Broken if it can't deal with the given options and players and Err
+// returns why.
 func New(dealer hand.Dealer, opts Options, playerIDs []string) *Table {
+	t, _ := newTable(dealer, opts, playerIDs)
+	return t
+}
+
+// NewWithError is like New but returns an error describing why the table
+// can't deal with the given options and players instead of a Broken table.
+func NewWithError(dealer hand.Dealer, opts Options, playerIDs []string) (*Table, error) {
+	t, err := newTable(dealer, opts, playerIDs)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func newTable(dealer hand.Dealer, opts Options, playerIDs []string) (*Table, error) {
+	err := opts.Validate()
+	if err == nil && len(playerIDs) < 2 {
+		err = errors.New("table: at least two players are needed")
+	}
+	if err == nil {
+		err = checkIDs(playerIDs)
+	}
+	if err == nil && len(playerIDs) > maxSeats(opts) {
+		err = fmt.Errorf("table: at most %d players can be seated", maxSeats(opts))
+	}
+	if err == nil && !deckFits(opts, len(playerIDs)) {
+		err = fmt.Errorf("table: not enough cards in the deck for %d players", len(playerIDs))
+	}
 	status := Dealing
-	if len(playerIDs) < 2 {
+	if err != nil {
 		status = Broken
 	}
 	seats := []*Player{}
@@ -88,9 +334,26 @@ func New(dealer hand.Dealer, opts Options, playerIDs []string) *Table {
 		round:   PreFlop,
 		status:  status,
 		dealer:  dealer,
+		err:     err,
+		sb:      -1,
+		bb:      -1,
+		chips:   len(seats) * opts.Buyin,
 	}
-	t.setupRound()
-	return t
+	if status == Dealing {
+		t.setupRound()
+	}
+	return t, err
+}
+
+func checkIDs(playerIDs []string) error {
+	seen := map[string]bool{}
+	for _, id := range playerIDs {
+		if seen[id] {
+			return fmt.Errorf("table: player %s is already seated", id)
+		}
+		seen[id] = true
+	}
+	return nil
 }
 
 type State struct {
@@ -101,30 +364,166 @@ type State struct {
 	Status  Status
 	Round   Round
 	Button  int
-	Cost    int
-	Pot     int
+	// DeadButton is set if the button is dead because the player due it
+	// left, in which case Button is the seat before where it would be.
+	DeadButton bool
+	Cost       int
+	Pot        int
+	// Contesting is the number of players still in the hand, who haven't
+	// folded or sat it out.
+	Contesting int
+	// MinRaise and MaxRaise are the bounds on the chips the active player
+	// may bet or raise, as in LegalActionsDetailed.  Both are the chips
+	// left after calling if the player is short of a full raise and zero
+	// if a bet or raise isn't possible.
+	MinRaise int
+	MaxRaise int
+	// CallAmount is the chips the active player puts in to call, which is
+	// less than they owe if it is their whole stack, and AllInAmount the
+	// chips they put in going all in.
+	CallAmount  int
+	AllInAmount int
+	// PotOdds is the share of the pot after calling that the call makes
+	// up, or zero if nothing is owed.
+	PotOdds float64
+	// Result is the outcome of the last completed hand or nil if no hand
+	// has been completed.
+	Result *Result
+}
+
+// Result is the outcome of a completed hand.  Winners and LowWinners are
+// the winners of the main pot and Pots breaks down the main pot followed
+// by any side pots.  If the board was run twice Boards holds both
+// runouts, Board is the first and each pot has a result for each board.
+// ShowdownHands holds the hand of every player at showdown on the first
+// board and is nil if the hand ended without one.  Shown holds the hole
+// cards of the players who showed.  Players who muck are left out of
+// both.  WinningHand is the hand that won the main pot at showdown on the
+// first board, including the five cards used, and is nil without one.
+// ShowdownOrder is the order the players who showed at showdown revealed
+// their cards in.  Returned is the part of the last bet or raise no one
+// called, which went back to ReturnedTo instead of into a pot.
+type Result struct {
+	Board         []hand.Card
+	Boards        [][]hand.Card
+	Winners       []string
+	LowWinners    []string
+	Pots          []PotResult
+	Raked         int
+	Returned      int
+	ReturnedTo    string
+	ShowdownHands map[string]HandInfo
+	Shown         map[string][]hand.Card
+	ShowdownOrder []string
+	WinningHand   *HandInfo
+}
+
+// HandInfo is a player's best hand at showdown.  Place is where the hand
+// ranks among those shown, starting at 1 for the best, with tied hands
+// sharing a place.
+type HandInfo struct {
+	Ranking     hand.Ranking
+	Description string
+	Cards       []hand.Card
+	Place       int
+}
+
+// PotResult is the outcome of a single pot.  Chips is the amount awarded
+// after rake.  LowWinners is only set in hi-lo games when a low qualifies.
+// Board is the index of the board in Result.Boards the pot was played on.
+// Hand describes the winning high hand if the pot went to showdown.
+type PotResult struct {
+	Contesting []string
+	Winners    []string
+	LowWinners []string
+	Chips      int
+	Board      int
+	Hand       *hand.Breakdown
 }
 
 func (t *Table) State() State {
+	t.mu.Lock()
+	defer t.unlock()
+	return t.state()
+}
+
+// StateFor returns the state as seen by the player, with every other
+// player's hole cards hidden.  Cards shown at the end of the last hand
+// are in Result.
+func (t *Table) StateFor(id string) State {
+	t.mu.Lock()
+	defer t.unlock()
+	s := t.state()
+	for i := range s.Seats {
+		if s.Seats[i].ID != id {
+			s.Seats[i].Cards = nil
+		}
+	}
+	if s.Active.ID != id {
+		s.Active.Cards = nil
+	}
+	return s
+}
+
+// SpectatorState returns the state as seen by someone not at the table,
+// with every player's hole cards hidden.  Cards shown at the end of the
+// last hand are in Result.
+func (t *Table) SpectatorState() State {
+	t.mu.Lock()
+	defer t.unlock()
+	s := t.state()
+	for i := range s.Seats {
+		s.Seats[i].Cards = nil
+	}
+	s.Active.Cards = nil
+	return s
+}
+
+func (t *Table) state() State {
 	seats := []Player{}
 	pot := 0
 	for _, seat := range t.seats {
 		seats = append(seats, *seat)
-		pot += seat.ChipsInPot
+		pot += seat.ChipsInPot + seat.DeadChips
+	}
+	if t.active == nil {
+		return State{
+			Options: t.options,
+			Seats:   seats,
+			Status:  t.status,
+			Result:  t.result,
+		}
+	}
+	minRaise, maxRaise := 0, 0
+	if legal := t.legalActions(); includes(legal, Bet) || includes(legal, Raise) {
+		minRaise, maxRaise = t.raiseBounds()
 	}
 	return State{
-		Options: t.options,
-		Seats:   seats,
-		Cards:   append([]hand.Card(nil), t.cards...),
-		Active:  *t.active,
-		Button:  t.button,
-		Cost:    t.cost,
-		Round:   t.round,
-		Status:  t.status,
-		Pot:     pot,
+		Options:     t.options,
+		Seats:       seats,
+		Cards:       append([]hand.Card(nil), t.cards...),
+		Active:      *t.active,
+		Button:      t.button,
+		DeadButton:  t.deadButton,
+		Cost:        t.cost,
+		Round:       t.round,
+		Status:      t.status,
+		Pot:         pot,
+		Contesting:  len(t.contesting()),
+		MinRaise:    minRaise,
+		MaxRaise:    maxRaise,
+		CallAmount:  t.callAmount(),
+		AllInAmount: t.active.Chips,
+		PotOdds:     potOdds(t.callAmount(), pot),
+		Result:      t.result,
 	}
 }
 
+// Action is a player's action.  Chips is only used by a Bet, where it is
+// the amount bet, and a Raise, where it is the amount raised by on top of
+// the chips owed to call rather than the total raised to.  Facing a bet
+// of 10 with nothing in the pot a Raise of 20 puts in 30 chips.  MinRaise
+// and MaxRaise in State are in the same terms.
 type Action struct {
 	Type  ActionType
 	Chips int
@@ -153,10 +552,12 @@ func (t *Table) Call() error {
 	return t.Act(Action{Type: Call})
 }
 
+// Bet bets the chips.
 func (t *Table) Bet(chips int) error {
 	return t.Act(Action{Type: Bet, Chips: chips})
 }
 
+// Raise calls and raises by the chips on top of the call.
 func (t *Table) Raise(chips int) error {
 	return t.Act(Action{Type: Raise, Chips: chips})
 }
@@ -165,30 +566,145 @@ func (t *Table) AllIn() error {
 	return t.Act(Action{Type: AllIn})
 }
 
+// BetPot bets or raises the size of the pot.
+func (t *Table) BetPot() error {
+	return t.BetFraction(1)
+}
+
+// BetHalfPot bets or raises half the size of the pot.
+func (t *Table) BetHalfPot() error {
+	return t.BetFraction(0.5)
+}
+
+// BetFraction bets the fraction of the pot, or raises it if facing a bet
+// in which case the pot includes the call, rounded down to whole chips.
+// The chips are kept within the smallest and largest bet or raise
+// allowed.
+func (t *Table) BetFraction(f float64) error {
+	t.mu.Lock()
+	defer t.unlock()
+	if f <= 0 {
+		return fmt.Errorf("table: fraction of the pot %v must be positive", f)
+	}
+	if t.status != Dealing {
+		return errors.New("table: no hand in progress")
+	}
+	a := Action{Type: Bet, Chips: int(f * float64(t.maxPotRaise()))}
+	if t.owed() > 0 {
+		a.Type = Raise
+	}
+	if a.Chips < t.minRaise() {
+		a.Chips = t.minRaise()
+	}
+	if maxRaise := t.maxRaise(); a.Chips > maxRaise {
+		a.Chips = maxRaise
+	}
+	return t.act(a)
+}
+
 func (t *Table) Act(a Action) error {
-	if includes(t.LegalActions(), a.Type) == false {
-		return errors.New("table: illegal action attempted")
+	t.mu.Lock()
+	defer t.unlock()
+	return t.act(a)
+}
+
+// CanAct returns the error Act would return for the action without
+// taking it, or nil if the active player may take it.
+func (t *Table) CanAct(a Action) error {
+	t.mu.Lock()
+	defer t.unlock()
+	return t.validate(a)
+}
+
+// CheckTimeout acts for the active player if they have run out of time
+// to act by now, checking if nothing is owed and folding otherwise.  It
+// returns the resulting state.
+func (t *Table) CheckTimeout(now time.Time) (State, error) {
+	t.mu.Lock()
+	defer t.unlock()
+	if t.status != Dealing {
+		return t.state(), errors.New("table: no hand in progress")
+	}
+	timeout := t.options.ActionTimeout
+	if timeout == 0 || now.Sub(t.activeSince) < timeout {
+		return t.state(), nil
+	}
+	a := Action{Type: Fold}
+	if t.owed() == 0 {
+		a.Type = Check
+	}
+	err := t.act(a)
+	return t.state(), err
+}
+
+// RunOut deals the rest of the board and awards the pot without waiting
+// for the players to check through each round once no more betting is
+// possible because every player left is all in.  The board is run out
+// automatically once the players go all in during a hand, so this is
+// only needed when the blinds put them all in.  It returns the state
+// with the hand's result.
+func (t *Table) RunOut() (State, error) {
+	t.mu.Lock()
+	defer t.unlock()
+	if t.status != Dealing {
+		return t.state(), errors.New("table: no hand in progress")
+	}
+	if !t.allIn() {
+		return t.state(), errors.New("table: players can still bet")
+	}
+	for _, seat := range t.contesting() {
+		if !seat.AllIn && t.owedBy(seat) > 0 {
+			return t.state(), fmt.Errorf("table: player %s has yet to call", seat.ID)
+		}
+	}
+	result := t.result
+	for t.result == result {
+		if err := t.act(Action{Type: Check}); err != nil {
+			return t.state(), err
+		}
+	}
+	return t.state(), nil
+}
+
+func (t *Table) act(a Action) error {
+	if err := t.validate(a); err != nil {
+		return err
 	}
-	// TODO enforce limits, min bets
+	chips := t.active.ChipsInPot
 	switch a.Type {
 	case Fold:
 		t.active.Folded = true
 	case Check:
 	case Call:
 		t.active.contribute(t.owed())
-	case Bet, Raise:
-		if a.Chips < t.options.Stakes.BigBlind {
-			return errors.New("table: bet or raise must be a minimum of the big blind")
-		}
+	case Bet:
+		t.active.contribute(a.Chips)
+		t.raise(a.Chips)
+		t.aggressor = t.active
+	case Raise:
 		t.active.contribute(t.owed())
 		t.active.contribute(a.Chips)
-		t.resetAction()
+		t.raise(a.Chips)
+		t.aggressor = t.active
 	case AllIn:
+		raise := t.active.Chips - t.owed()
 		t.active.contribute(t.owed())
 		t.active.contribute(t.active.Chips)
-		t.resetAction()
+		if raise > 0 {
+			t.raise(raise)
+			t.aggressor = t.active
+		}
 	}
 	t.active.Acted = true
+	t.record(Event{
+		Type:     ActionTaken,
+		PlayerID: t.active.ID,
+		Action:   a.Type,
+		Chips:    t.active.ChipsInPot - chips,
+	})
+	if t.options.Logger != nil {
+		t.unlogged = append(t.unlogged, fmt.Sprintf("%s %ss", t.active.ID, strings.ToLower(a.Type.String())))
+	}
 	if t.active.ChipsInPot > t.cost {
 		t.cost = t.active.ChipsInPot
 	}
@@ -196,7 +712,80 @@ func (t *Table) Act(a Action) error {
 	return nil
 }
 
+// validate returns an error if the active player can't take the action.
+func (t *Table) validate(a Action) error {
+	if t.status != Dealing {
+		return errors.New("table: no hand in progress")
+	}
+	switch {
+	case a.Type == Bet && t.owed() > 0:
+		return fmt.Errorf("table: cannot bet when %d chips are owed, raise instead", t.owed())
+	case a.Type == Raise && t.owed() == 0:
+		return errors.New("table: cannot raise when nothing is owed, bet instead")
+	case a.Type == Check && t.owed() > 0:
+		return fmt.Errorf("table: cannot check when %d chips are owed, call or fold instead", t.owed())
+	case a.Type == Call && t.owed() <= 0:
+		return errors.New("table: cannot call when nothing is owed, check instead")
+	}
+	if includes(t.legalActions(), a.Type) == false {
+		return errors.New("table: illegal action attempted")
+	}
+	if a.Type == Bet || a.Type == Raise {
+		return t.validateBet(a)
+	}
+	return nil
+}
+
+// validateBet returns an error if the chips bet or raised are outside
+// the active player's stack, the minimum raise or the game's limit.
+func (t *Table) validateBet(a Action) error {
+	name := strings.ToLower(a.Type.String())
+	if a.Chips <= 0 {
+		return fmt.Errorf("table: %s must be of at least one chip", name)
+	}
+	allIn := t.owed()+a.Chips == t.active.Chips
+	if t.owed()+a.Chips > t.active.Chips {
+		return fmt.Errorf("table: %s of %d chips exceeds the %d chips available, go all in instead", name, a.Chips, t.active.Chips-t.owed())
+	}
+	if a.Chips < t.minRaise() && !allIn {
+		return fmt.Errorf("table: %s must be a minimum of %d chips", name, t.minRaise())
+	}
+	if limit := t.betLimit().maxRaise(t); a.Chips > limit {
+		return fmt.Errorf("table: %s exceeds the limit of %d chips", name, limit)
+	}
+	return nil
+}
+
+// raise records a bet or raise of the given chips, which reopens the
+// betting if it is a full bet or raise.  An all in for less than a full
+// raise only lets the players who have already acted call or fold.
+func (t *Table) raise(chips int) {
+	if chips <= 0 {
+		return
+	}
+	if chips < t.minRaise() {
+		for _, seat := range t.seats {
+			if seat.Acted {
+				seat.Acted = false
+				seat.Capped = true
+			}
+		}
+		return
+	}
+	t.lastRaise = chips
+	t.bets++
+	t.resetAction()
+	// once betting is capped everyone left to act may only call or fold
+	if n := t.maxRaises(); n > 0 && t.bets > n {
+		for _, seat := range t.seats {
+			seat.Capped = true
+		}
+	}
+}
+
 func (t *Table) Seats() []Player {
+	t.mu.Lock()
+	defer t.unlock()
 	seats := []Player{}
 	for _, seat := range t.seats {
 		seats = append(seats, *seat)
@@ -204,233 +793,1437 @@ func (t *Table) Seats() []Player {
 	return seats
 }
 
+// LastAggressor returns the last player to bet or raise in the current
+// round, including by going all in for more than the call, or nil if no
+// one has.  Forced bets such as the blinds don't count.
+func (t *Table) LastAggressor() *Player {
+	t.mu.Lock()
+	defer t.unlock()
+	if t.aggressor == nil {
+		return nil
+	}
+	p := *t.aggressor
+	return &p
+}
+
+// LegalActions returns the actions the active player may take, or nil
+// if no one is to act because the table is Broken or Waiting.
 func (t *Table) LegalActions() []ActionType {
-	if t.owed() == 0 {
-		return []ActionType{Fold, Check, Bet, AllIn}
+	t.mu.Lock()
+	defer t.unlock()
+	if t.active == nil {
+		return nil
 	}
-	if t.owed() > t.active.Chips {
+	return t.legalActions()
+}
+
+// LegalAction is an action the active player may take with the bounds on
+// its chips.  For a Bet or Raise Min and Max bound the Chips of the
+// Action, and for a Call or AllIn both are the chips the player puts in.
+type LegalAction struct {
+	Type ActionType
+	Min  int
+	Max  int
+}
+
+// LegalActionsDetailed returns the legal actions with their chips.
+func (t *Table) LegalActionsDetailed() []LegalAction {
+	t.mu.Lock()
+	defer t.unlock()
+	if t.active == nil {
+		return nil
+	}
+	actions := []LegalAction{}
+	for _, a := range t.legalActions() {
+		action := LegalAction{Type: a}
+		switch a {
+		case Bet, Raise:
+			action.Min, action.Max = t.raiseBounds()
+		case Call:
+			action.Min, action.Max = t.callAmount(), t.callAmount()
+		case AllIn:
+			action.Min, action.Max = t.active.Chips, t.active.Chips
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+func (t *Table) legalActions() []ActionType {
+	if t.active.Capped && t.owed() == 0 {
+		return []ActionType{Fold, Check}
+	}
+	if t.owed() > t.active.Chips || t.active.Capped {
 		return []ActionType{Fold, Call}
 	}
-	return []ActionType{Fold, Call, Raise, AllIn}
+	actions := []ActionType{Fold, Call, Raise}
+	if t.owed() == 0 {
+		actions = []ActionType{Fold, Check, Bet}
+	}
+	// a player whose call puts them all in has nothing left to raise
+	if t.active.Chips <= t.owed() {
+		actions = actions[:2]
+	}
+	// a shove is only legal if it fits within the limit
+	if t.active.Chips-t.owed() <= t.betLimit().maxRaise(t) {
+		actions = append(actions, AllIn)
+	}
+	return actions
 }
 
 func (t *Table) update() {
 	seat := t.nextToAct()
-	if seat != -1 {
+	if seat != -1 && len(t.contesting()) > 1 {
 		t.active = t.seats[seat]
+		t.activeSince = time.Now()
+		t.autoAct()
 		return
 	}
-	if len(t.contesting()) == 1 || t.round == River {
-		t.payout()
+	if len(t.contesting()) == 1 || t.round == t.lastRound() {
+		t.payout([][]hand.Card{t.cards})
+		t.round = PreFlop
+	} else if t.options.RunItTwice && t.allIn() {
+		t.payout(t.runItTwice())
+		t.round = PreFlop
+	} else if t.allIn() {
+		// no one is left to bet so the rest of the cards are dealt
+		// without stopping for action
+		for t.round < t.lastRound() {
+			t.round++
+			t.setupRound()
+		}
+		t.payout([][]hand.Card{t.cards})
 		t.round = PreFlop
 	} else {
-		t.round = (t.round + 1) % (River + 1)
+		t.round++
 	}
 	t.setupRound()
+	t.autoAct()
 }
 
-func (t *Table) Active() *Player {
-	return t.active
-}
-
-func (t *Table) setupRound() {
+// autoAct acts for the active player if they are defaulting, checking if
+// they can and folding otherwise.  Players already all in are never
+// folded so they keep their claim to the pot.  Nothing is done once
+// every player dealt in is defaulting so the table waits for someone.
+func (t *Table) autoAct() {
+	if t.status != Dealing || t.active == nil || !t.active.Defaulting {
+		return
+	}
+	present := false
 	for _, seat := range t.seats {
-		if seat != nil {
-			seat.Acted = false
-		}
+		present = present || !seat.SittingOut && !seat.Defaulting
 	}
-	switch t.round {
-	case PreFlop:
-		t.button = t.nextSeat(t.button)
-		sb := t.nextSeat(t.button)
-		bb := t.nextSeat(sb)
-		if t.occupiedSeats() == 2 {
-			sb = t.button
-			bb = t.nextSeat(t.button)
-		}
-		t.deck = t.dealer.Deck()
-		for _, seat := range t.seats {
-			if seat != nil {
-				seat.Cards = t.deck.PopMulti(2)
-				seat.ChipsInPot = 0
-				seat.Acted = false
-				seat.Folded = false
-				seat.AllIn = false
-				seat.contribute(t.options.Stakes.Ante)
-			}
-		}
-		t.seats[sb].contribute(t.options.Stakes.SmallBlind)
-		t.seats[bb].contribute(t.options.Stakes.BigBlind)
-		action := t.nextSeat(bb)
-		t.active = t.seats[action]
-		t.cost = t.options.Stakes.BigBlind
-	case Flop:
-		t.cards = t.deck.PopMulti(3)
-		action := t.nextSeat(t.button)
-		t.active = t.seats[action]
-	case Turn, River:
-		t.cards = append(t.cards, t.deck.Pop())
-		action := t.nextSeat(t.button)
-		t.active = t.seats[action]
+	if !present {
+		return
+	}
+	a := Action{Type: Fold}
+	if t.owed() <= 0 {
+		a.Type = Check
 	}
+	t.act(a)
 }
 
-func (t *Table) payout() {
-	hands := map[*Player]*hand.Hand{}
-	for _, seat := range t.seats {
-		hands[seat] = hand.New(append(seat.Cards, t.cards...))
+// SetPlayerDefaulting sets whether the player is defaulting, such as when
+// they have disconnected, in which case the table acts for them.
+func (t *Table) SetPlayerDefaulting(id string, defaulting bool) error {
+	t.mu.Lock()
+	defer t.unlock()
+	p := t.player(id)
+	if p == nil {
+		return errors.New("table: player not found")
 	}
-	for _, pot := range t.pots() {
-		// sort by best hand first
-		sort.Slice(pot.contesting, func(i, j int) bool {
-			iHand := hands[pot.contesting[i]]
-			jHand := hands[pot.contesting[j]]
-			return iHand.CompareTo(jHand) > 0
-		})
-		// select winners who split pot if more than one
-		winners := []*Player{}
-		h1 := hands[pot.contesting[0]]
-		for _, seat := range pot.contesting {
-			h2 := hands[seat]
-			if h1.CompareTo(h2) != 0 {
-				break
-			}
-			winners = append(winners, seat)
-		}
-		// sort closest to the button for spare chips in split pot
-		sort.Slice(winners, func(i, j int) bool {
-			iDist := t.distanceFromButton(winners[i])
-			jDist := t.distanceFromButton(winners[j])
-			return iDist < jDist
-		})
-		// payout chips
-		for i, seat := range winners {
-			seat.Chips += pot.chips / len(winners)
-			if (pot.chips % len(winners)) > i {
-				seat.Chips++
-			}
-		}
+	p.Defaulting = defaulting
+	if p == t.active {
+		t.autoAct()
 	}
+	return nil
 }
 
-type sidePot struct {
-	contesting []*Player
-	chips      int
-}
-
-func (t *Table) pots() []*sidePot {
-	contesting := t.contesting()
-	sort.Slice(contesting, func(i, j int) bool {
-		return contesting[i].ChipsInPot < contesting[j].ChipsInPot
-	})
-	costs := []int{}
-	for _, seat := range contesting {
-		if contains(costs, seat.ChipsInPot) == false {
-			costs = append(costs, seat.ChipsInPot)
-		}
-	}
-	pots := []*sidePot{}
-	for i, cost := range costs {
-		pot := &sidePot{}
-		min := 0
-		if i != 0 {
-			min = costs[i-1]
-		}
-		for _, seat := range t.seats {
-			pot.chips += max(seat.ChipsInPot-min, 0)
-		}
-		for _, seat := range contesting {
-			if seat.ChipsInPot >= cost {
-				pot.contesting = append(pot.contesting, seat)
-			}
-		}
-		pots = append(pots, pot)
+// Verify returns an error if chips have been lost or created, checking
+// that the chips on the table are those bought in less any taken by
+// departing players and the rake.
+func (t *Table) Verify() error {
+	t.mu.Lock()
+	defer t.unlock()
+	if total := t.totalChips(); total != t.chips {
+		return fmt.Errorf("table: %d chips on the table but expected %d", total, t.chips)
 	}
-	return pots
+	return nil
 }
 
-func (t *Table) resetAction() {
+// totalChips returns the chips in every player's stack and in the pot.
+func (t *Table) totalChips() int {
+	total := 0
 	for _, seat := range t.seats {
-		if seat != nil {
-			seat.Acted = false
-		}
+		total += seat.Chips + seat.ChipsInPot + seat.DeadChips
 	}
+	return total
 }
 
-func (t *Table) nextSeat(seat int) int {
-	for {
-		seat = (seat + 1) % len(t.seats)
-		p := t.seats[seat]
-		if p != nil {
-			return seat
-		}
-	}
+// Err returns why the table is Broken or nil if it isn't.
+func (t *Table) Err() error {
+	t.mu.Lock()
+	defer t.unlock()
+	return t.err
 }
 
-func (t *Table) nextToAct() int {
-	count := 0
-	seat := t.active.Seat
-	for {
-		seat = t.nextSeat(seat)
-		p := t.seats[seat]
-		if !p.Acted && !p.AllIn && !p.Folded {
-			return p.Seat
-		}
-		count++
-		if count == t.occupiedSeats()-1 {
-			return -1
-		}
+// Active returns a copy of the player whose turn it is to act.
+func (t *Table) Active() *Player {
+	t.mu.Lock()
+	defer t.unlock()
+	if t.active == nil {
+		return nil
 	}
+	p := *t.active
+	return &p
 }
 
-func (t *Table) occupiedSeats() int {
-	count := 0
-	for _, seat := range t.seats {
-		if seat != nil {
-			count++
+// PendingActors returns the IDs of the players still to act this round in
+// order, starting with the active player and going clockwise.  Players
+// who have folded, are all in or are sitting out are left out.
+func (t *Table) PendingActors() []string {
+	t.mu.Lock()
+	defer t.unlock()
+	if t.active == nil {
+		return nil
+	}
+	ids := []string{}
+	for i := 0; i < len(t.seats); i++ {
+		p := t.seats[(t.active.Seat+i)%len(t.seats)]
+		if !p.SittingOut && !p.Acted && !p.AllIn && !p.Folded {
+			ids = append(ids, p.ID)
 		}
 	}
-	return count
-}
-
-func (t *Table) owed() int {
-	return t.cost - t.active.ChipsInPot
+	return ids
 }
 
-func (t *Table) distanceFromButton(p *Player) int {
-	seat := t.button
-	dist := 0
-	for {
-		seat = t.nextSeat(seat)
-		dist++
-		if p.Seat == seat {
-			return dist
-		}
+// EffectiveStack returns the smaller of the two players' chips left to
+// bet, which is the most either can win from or lose to the other, or 0
+// if either isn't seated.
+func (t *Table) EffectiveStack(id1, id2 string) int {
+	t.mu.Lock()
+	defer t.unlock()
+	p1, p2 := t.player(id1), t.player(id2)
+	if p1 == nil || p2 == nil {
+		return 0
+	}
+	if p1.Chips < p2.Chips {
+		return p1.Chips
 	}
+	return p2.Chips
 }
 
-func (t *Table) contesting() []*Player {
-	contesting := []*Player{}
-	for _, seat := range t.seats {
-		if seat.Folded == false {
-			contesting = append(contesting, seat)
+// PotEligibility returns the indices of the pots each player still in the
+// hand can win, with the main pot first, as the pots would be split if
+// the hand ended now.
+func (t *Table) PotEligibility() map[string][]int {
+	t.mu.Lock()
+	defer t.unlock()
+	eligible := map[string][]int{}
+	for i, pot := range t.pots() {
+		for _, seat := range pot.contesting {
+			eligible[seat.ID] = append(eligible[seat.ID], i)
 		}
 	}
-	return contesting
+	return eligible
 }
 
-type Player struct {
-	ID         string
-	Seat       int
-	Chips      int
-	ChipsInPot int
-	Acted      bool
-	Folded     bool
-	AllIn      bool
-	Cards      []hand.Card
-}
+func (t *Table) setupRound() {
+	t.activeSince = time.Now()
+	t.resetAction()
+	t.lastRaise = 0
+	t.bets = 0
+	t.aggressor = nil
+	if t.round > PreFlop && t.options.Variant == SevenCardStud {
+		// the last card is dealt face down
+		t.dealStreet(t.round != SeventhStreet)
+		t.active = t.seats[t.bestShowing()]
+		return
+	}
+	switch t.round {
+	case PreFlop:
+		button, sb, bb := t.nextPositions()
+		t.removeLeaving()
+		for _, seat := range t.seats {
+			seat.SittingOut = seat.Chips == 0 || seat.Away || t.waiting(seat) && seat != bb
+			if seat == bb {
+				seat.MissedBlinds = 0
+			}
+			if !seat.SittingOut {
+				seat.Entering = false
+			}
+		}
+		if p := t.nextButton; p != nil {
+			t.nextButton = nil
+			// the blinds follow a moved button as they do on the first
+			// hand, which advances the button onto the player
+			if !p.Leaving && !p.SittingOut {
+				t.button = (p.Seat - 1 + len(t.seats)) % len(t.seats)
+				button, sb, bb = nil, nil, nil
+			}
+		}
+		if t.dealtIn() < 2 {
+			t.status = Waiting
+			t.active = nil
+			return
+		}
+		if t.options.HandsPerLevel > 0 && t.hands == t.options.HandsPerLevel {
+			t.advanceLevel()
+		}
+		t.hands++
+		t.placeButton(button, sb, bb)
+		t.lastEvents = t.events
+		t.events = nil
+		started := Event{Type: HandStarted}
+		if !t.deadButton {
+			started.PlayerID = t.seats[t.button].ID
+		}
+		t.record(started)
+		t.cards = nil
+		t.deck = t.dealer.Deck()
+		t.lastShuffle = t.shuffle
+		t.shuffle = nil
+		if c, ok := t.dealer.(committer); ok {
+			seed, deck := c.RevealShuffle()
+			t.shuffle = &hand.Shuffle{Seed: seed, Deck: deck}
+		}
+		if t.options.Variant == ShortDeck {
+			t.deck = shortDeck(t.deck)
+		}
+		t.fingerprint = t.deck.Fingerprint()
+		for _, seat := range t.seats {
+			seat.Cards = nil
+			seat.UpCards = nil
+			seat.ChipsInPot = 0
+			seat.DeadChips = 0
+			seat.Acted = false
+			seat.Folded = false
+			seat.AllIn = false
+			seat.Mucked = false
+			seat.Shown = false
+			if !seat.SittingOut {
+				seat.Cards = t.deck.PopMulti(holeCards(t.options))
+				// antes are dead money so don't count toward calls and a
+				// big blind ante is posted with the blinds
+				if t.options.BigBlindAnte {
+					continue
+				}
+				if chips := seat.postDead(t.stakes().Ante); chips > 0 {
+					t.record(Event{Type: AntePosted, PlayerID: seat.ID, Chips: chips})
+				}
+			}
+		}
+		action := t.forcedBets().post(t)
+		t.active = t.seats[t.firstToAct(action)]
+	case Flop:
+		t.burn()
+		t.cards = t.deck.PopMulti(3)
+		t.record(Event{Type: BoardDealt, Cards: t.cards})
+		action := t.nextInHand(t.button)
+		t.active = t.seats[t.firstToAct(action)]
+	case Turn, River:
+		t.burn()
+		card := t.deck.Pop()
+		t.cards = append(t.cards, card)
+		t.record(Event{Type: BoardDealt, Cards: []hand.Card{card}})
+		action := t.nextInHand(t.button)
+		t.active = t.seats[t.firstToAct(action)]
+	}
+}
+
+// resume deals the next hand on a Waiting table once at least two players
+// have chips and aren't sitting out.  The table keeps waiting if players
+// waiting for the big blind leave it short.
+func (t *Table) resume() {
+	if t.status != Waiting {
+		return
+	}
+	ready := 0
+	for _, seat := range t.seats {
+		if seat.Chips > 0 && !seat.Away && !seat.Leaving {
+			ready++
+		}
+	}
+	if ready < 2 {
+		return
+	}
+	t.status = Dealing
+	t.setupRound()
+	t.autoAct()
+}
+
+// AddPlayer seats a new player with a buyin.  The player sits out until
+// the next hand is dealt, which is straight away if the table was waiting
+// for players, and with the PostToEnter option is marked MustPost until
+// they have posted a big blind.
+func (t *Table) AddPlayer(id string) error {
+	t.mu.Lock()
+	defer t.unlock()
+	if t.status == Broken {
+		return t.err
+	}
+	if t.player(id) != nil {
+		return fmt.Errorf("table: player %s is already seated", id)
+	}
+	if len(t.seats) >= maxSeats(t.options) || !deckFits(t.options, len(t.seats)+1) {
+		return errors.New("table: no seats left")
+	}
+	t.chips += t.options.Buyin
+	t.seats = append(t.seats, &Player{
+		ID:         id,
+		Seat:       len(t.seats),
+		Chips:      t.options.Buyin,
+		SittingOut: true,
+		MustPost:   t.options.PostToEnter,
+		Entering:   true,
+	})
+	t.resume()
+	return nil
+}
+
+// BuyPlayerIn resets the player's stack to exactly the buyin, whatever
+// it was before, such as when a busted player rebuys.  Use TopUp to add
+// to an existing stack.  Players still contesting the hand can't buy in.
+// A table waiting for players deals the next hand once the player has
+// chips.
+func (t *Table) BuyPlayerIn(id string) error {
+	t.mu.Lock()
+	defer t.unlock()
+	p, err := t.betweenHands(id)
+	if err != nil {
+		return err
+	}
+	t.chips += t.options.Buyin - p.Chips
+	p.Chips = t.options.Buyin
+	t.resume()
+	return nil
+}
+
+// TopUp adds chips to the player's stack, up to the MaxStack option if
+// set.  Players still contesting the hand can't top up.  As with
+// BuyPlayerIn, a table waiting for players deals the next hand.
+func (t *Table) TopUp(id string, amount int) error {
+	t.mu.Lock()
+	defer t.unlock()
+	if amount <= 0 {
+		return fmt.Errorf("table: top up of %d chips must be positive", amount)
+	}
+	p, err := t.betweenHands(id)
+	if err != nil {
+		return err
+	}
+	if maxStack := t.options.MaxStack; maxStack > 0 {
+		if p.Chips >= maxStack {
+			return fmt.Errorf("table: player %s already has the max stack of %d", id, maxStack)
+		}
+		if p.Chips+amount > maxStack {
+			amount = maxStack - p.Chips
+		}
+	}
+	t.chips += amount
+	p.Chips += amount
+	t.resume()
+	return nil
+}
+
+// betweenHands returns the player if they aren't contesting the hand in
+// progress.
+func (t *Table) betweenHands(id string) (*Player, error) {
+	p := t.player(id)
+	if p == nil {
+		return nil, errors.New("table: player not found")
+	}
+	if t.status == Dealing && (p == t.active || (!p.Folded && !p.SittingOut)) {
+		return nil, fmt.Errorf("table: player %s is in a hand", id)
+	}
+	return p, nil
+}
+
+// Muck hides the player's cards when the hand ends unless they are needed
+// to win a pot at showdown.
+func (t *Table) Muck(id string) error {
+	t.mu.Lock()
+	defer t.unlock()
+	p := t.player(id)
+	if p == nil || p.SittingOut {
+		return errors.New("table: player not in the hand")
+	}
+	p.Mucked, p.Shown = true, false
+	return nil
+}
+
+// Show reveals the player's cards when the hand ends, even if they fold
+// or everyone else does.
+func (t *Table) Show(id string) error {
+	t.mu.Lock()
+	defer t.unlock()
+	p := t.player(id)
+	if p == nil || p.SittingOut {
+		return errors.New("table: player not in the hand")
+	}
+	p.Mucked, p.Shown = false, true
+	return nil
+}
+
+// RemovePlayer removes the player from the table once the current hand
+// is over, or straight away if no hand is in progress.  Players still
+// contesting the hand can't be removed.
+func (t *Table) RemovePlayer(id string) error {
+	t.mu.Lock()
+	defer t.unlock()
+	p := t.player(id)
+	if p == nil {
+		return errors.New("table: player not found")
+	}
+	if t.status == Dealing && (p == t.active || (!p.Folded && !p.SittingOut)) {
+		return errors.New("table: cannot remove a player during a hand")
+	}
+	p.Leaving = true
+	if t.status != Dealing {
+		t.removeLeaving()
+	}
+	return nil
+}
+
+// SetButton moves the button to the seat for the next hand, such as when
+// a tournament moves players between tables, with the blinds following
+// it.  The player in the seat must be dealt in.
+func (t *Table) SetButton(seat int) error {
+	t.mu.Lock()
+	defer t.unlock()
+	if seat < 0 || seat >= len(t.seats) {
+		return fmt.Errorf("table: seat %d not found", seat)
+	}
+	p := t.seats[seat]
+	if p.Leaving || p.Away || p.Chips == 0 {
+		return fmt.Errorf("table: player %s in seat %d is sitting out", p.ID, seat)
+	}
+	t.nextButton = p
+	return nil
+}
+
+// waiting returns whether the player must wait for the big blind or post
+// their missed blinds before being dealt in again, or post a blind before
+// being dealt in for the first time.
+func (t *Table) waiting(p *Player) bool {
+	if p.MustPost {
+		return false
+	}
+	return t.options.PostMissedBlinds && p.MissedBlinds > 0 || t.options.WaitForBigBlind && p.Entering
+}
+
+// SitOut sits the player out from the next hand until they SitIn.
+func (t *Table) SitOut(id string) error {
+	t.mu.Lock()
+	defer t.unlock()
+	p := t.player(id)
+	if p == nil {
+		return errors.New("table: player not found")
+	}
+	p.Away = true
+	return nil
+}
+
+// SitIn deals the player back in from the next hand, which is dealt
+// straight away if the table was waiting for them.  With the
+// PostMissedBlinds option a player who missed the big blind waits for it
+// unless they call PostMissedBlind.
+func (t *Table) SitIn(id string) error {
+	t.mu.Lock()
+	defer t.unlock()
+	p := t.player(id)
+	if p == nil {
+		return errors.New("table: player not found")
+	}
+	p.Away = false
+	if !t.options.PostMissedBlinds {
+		p.MissedBlinds = 0
+	}
+	t.resume()
+	return nil
+}
+
+// PostMissedBlind has a player who missed the big blind post it along
+// with a dead small blind to be dealt in next hand without waiting.
+func (t *Table) PostMissedBlind(id string) error {
+	t.mu.Lock()
+	defer t.unlock()
+	p := t.player(id)
+	if p == nil {
+		return errors.New("table: player not found")
+	}
+	if p.MissedBlinds == 0 {
+		return fmt.Errorf("table: player %s hasn't missed any blinds", id)
+	}
+	p.MustPost = true
+	return nil
+}
+
+// PostBlind has a player added to the table post a big blind to be dealt
+// in next hand instead of waiting for the big blind with the
+// WaitForBigBlind option.  A player entering in the small blind posts the
+// difference.
+func (t *Table) PostBlind(id string) error {
+	t.mu.Lock()
+	defer t.unlock()
+	p := t.player(id)
+	if p == nil {
+		return errors.New("table: player not found")
+	}
+	if !p.Entering {
+		return fmt.Errorf("table: player %s has already been dealt in", id)
+	}
+	p.MustPost = true
+	t.resume()
+	return nil
+}
+
+// nextPositions returns the players due the button and blinds next hand,
+// found before any players leave.  The big blind moves on to the next
+// player each hand so no one dodges it or posts it twice in a row, and
+// the small blind and button follow it.  The small blind or button is
+// nil, and dead, if the player due it is leaving or out of chips.  All
+// are nil before the first hand.
+func (t *Table) nextPositions() (button, sb, bb *Player) {
+	if t.bb < 0 {
+		return nil, nil, nil
+	}
+	staying := func(p *Player) bool {
+		return !p.Leaving && !p.Away && p.Chips > 0
+	}
+	// the big blind passes players sitting out who miss it, while those
+	// waiting to come back take it
+	for i := 1; i < len(t.seats); i++ {
+		p := t.seats[(t.bb+i)%len(t.seats)]
+		if staying(p) {
+			bb = p
+			break
+		}
+		if p.Away && !p.Leaving && p.Chips > 0 {
+			p.MissedBlinds++
+		}
+	}
+	if p := t.seats[t.bb]; staying(p) {
+		sb = p
+	}
+	if t.sb >= 0 && staying(t.seats[t.sb]) {
+		button = t.seats[t.sb]
+	}
+	return button, sb, bb
+}
+
+// placeButton sets the button and blinds for the hand from the players
+// returned by nextPositions.  Heads up the button posts the small blind
+// so acts first before the flop and last after it.  A dead button is
+// placed on the seat before the first blind so action still starts from
+// the blinds after the flop, as it would if the departed player's seat
+// were still there.
+func (t *Table) placeButton(button, sb, bb *Player) {
+	t.deadButton = false
+	switch {
+	case t.headsUp():
+		if bb == nil {
+			t.button = t.nextSeat(t.button)
+			bb = t.seats[t.nextSeat(t.button)]
+		}
+		t.bb = bb.Seat
+		t.sb = t.nextSeat(t.bb)
+		t.button = t.sb
+	case bb == nil:
+		t.button = t.nextSeat(t.button)
+		t.sb = t.nextSeat(t.button)
+		t.bb = t.nextSeat(t.sb)
+	default:
+		t.bb = bb.Seat
+		t.sb = -1
+		first := t.bb
+		if sb != nil {
+			t.sb = sb.Seat
+			first = t.sb
+		}
+		t.button = (first - 1 + len(t.seats)) % len(t.seats)
+		// coming from heads up the big blind had the button last hand
+		if button != nil && button != bb {
+			t.button = button.Seat
+		}
+		t.deadButton = button == nil
+	}
+}
+
+// removeLeaving removes the players marked as leaving.  Positions for the
+// next hand are found beforehand by nextPositions.  If anyone leaves the
+// button moves to the last seat left at or before it and the blinds are
+// cleared, so a table waiting for players places them from the button as
+// on the first hand.
+func (t *Table) removeLeaving() {
+	seats := []*Player{}
+	button := -1
+	for i, seat := range t.seats {
+		if seat.Leaving {
+			t.chips -= seat.Chips
+			continue
+		}
+		if i <= t.button {
+			button = len(seats)
+		}
+		seats = append(seats, seat)
+	}
+	if len(seats) == len(t.seats) {
+		return
+	}
+	for i, seat := range seats {
+		seat.Seat = i
+	}
+	if button < 0 {
+		button = max(len(seats)-1, 0)
+	}
+	t.seats = seats
+	t.button = button
+	t.sb, t.bb = -1, -1
+}
+
+// CurrentLevel returns the index of the current level of the blind
+// schedule.
+func (t *Table) CurrentLevel() int {
+	t.mu.Lock()
+	defer t.unlock()
+	return t.level
+}
+
+// AdvanceBlindLevel moves to the next level of the blind schedule starting
+// with the next hand.  The last level is kept once it is reached.
+func (t *Table) AdvanceBlindLevel() {
+	t.mu.Lock()
+	defer t.unlock()
+	t.advanceLevel()
+}
+
+func (t *Table) advanceLevel() {
+	if t.level < len(t.options.BlindSchedule)-1 {
+		t.level++
+	}
+	t.hands = 0
+}
+
+// stakes returns the stakes for the current level of the blind schedule
+// or the fixed stakes if there is no schedule.
+func (t *Table) stakes() Stakes {
+	if len(t.options.BlindSchedule) == 0 {
+		return t.options.Stakes
+	}
+	return t.options.BlindSchedule[t.level]
+}
+
+// post forces the player to put chips in the pot and records it.
+func (t *Table) post(p *Player, typ EventType, chips int) {
+	t.record(Event{Type: typ, PlayerID: p.ID, Chips: p.contribute(chips)})
+}
+
+// payout returns any uncalled bet and awards each pot, split evenly
+// between the boards if the hand was run out more than once.  Only the
+// hands of players still in the hand are evaluated.
+func (t *Table) payout(boards [][]hand.Card) {
+	returnedTo, returned := t.returnUncalled()
+	if returned > 0 {
+		t.record(Event{Type: BetReturned, PlayerID: returnedTo, Chips: returned})
+	}
+	hands := make([]map[*Player]*hand.Hand, len(boards))
+	lows := make([]map[*Player]lowHand, len(boards))
+	for i, board := range boards {
+		hands[i] = map[*Player]*hand.Hand{}
+		lows[i] = map[*Player]lowHand{}
+		for _, seat := range t.contesting() {
+			hands[i][seat] = t.handFor(seat, board)
+			if t.options.Variant == OmahaHiLo {
+				lows[i][seat] = bestOmahaLow(seat.Cards, board)
+			}
+		}
+	}
+	result := &Result{Board: boards[0], Boards: boards, Returned: returned, ReturnedTo: returnedTo}
+	// the pots are awarded once the players have shown
+	awards := []Event{}
+	for _, pot := range t.pots() {
+		// no flop, no drop
+		if len(boards[0]) > 0 || t.options.Variant == SevenCardStud && t.round > PreFlop {
+			rake := t.rake(pot.chips, result.Raked)
+			pot.chips -= rake
+			result.Raked += rake
+		}
+		contesting := []string{}
+		for _, seat := range pot.contesting {
+			contesting = append(contesting, seat.ID)
+		}
+		for i := range boards {
+			// the odd chip between boards goes to the first board
+			chips := pot.chips / len(boards)
+			if pot.chips%len(boards) > i {
+				chips++
+			}
+			potResult := PotResult{Contesting: contesting, Chips: chips, Board: i}
+			// the low half of a split pot goes to the low and the odd
+			// chip to the high
+			high := chips
+			if lowWinners := bestLows(pot.contesting, lows[i]); len(lowWinners) > 0 {
+				high -= chips / 2
+				potResult.LowWinners = t.award(lowWinners, chips/2, &awards)
+			}
+			winners := bestHands(pot.contesting, t.comparer(hands[i], boards[i]))
+			if len(pot.contesting) > 1 {
+				b := hands[i][winners[0]].Breakdown()
+				potResult.Hand = &b
+			}
+			potResult.Winners = t.award(winners, high, &awards)
+			result.Pots = append(result.Pots, potResult)
+		}
+	}
+	if len(result.Pots) > 0 {
+		result.Winners = result.Pots[0].Winners
+		result.LowWinners = result.Pots[0].LowWinners
+		// the main pot is played on every board
+		for _, pot := range result.Pots[1:len(boards)] {
+			result.Winners = union(result.Winners, pot.Winners)
+			result.LowWinners = union(result.LowWinners, pot.LowWinners)
+		}
+	}
+	t.chips -= result.Raked
+	t.show(result, hands[0])
+	for _, e := range awards {
+		t.record(e)
+	}
+	// the pot has been awarded which matters if no hand follows this one
+	for _, seat := range t.seats {
+		seat.ChipsInPot = 0
+		seat.DeadChips = 0
+	}
+	t.result = result
+}
+
+// returnUncalled gives the part of the biggest bet no one else matched
+// back to the player who made it, returning their ID and the chips.
+func (t *Table) returnUncalled() (string, int) {
+	var top *Player
+	for _, seat := range t.seats {
+		if top == nil || seat.ChipsInPot > top.ChipsInPot {
+			top = seat
+		}
+	}
+	called := 0
+	for _, seat := range t.seats {
+		if seat != top {
+			called = max(called, seat.ChipsInPot)
+		}
+	}
+	if top == nil || top.ChipsInPot <= called {
+		return "", 0
+	}
+	chips := top.ChipsInPot - called
+	top.ChipsInPot -= chips
+	top.Chips += chips
+	return top.ID, chips
+}
+
+// runItTwice deals two runouts of the rest of the board from the deck,
+// burning a card before each street, and returns both boards.
+func (t *Table) runItTwice() [][]hand.Card {
+	boards := [][]hand.Card{}
+	for i := 0; i < 2; i++ {
+		board := append([]hand.Card{}, t.cards...)
+		for len(board) < 5 {
+			n := 1
+			if len(board) == 0 {
+				n = 3
+			}
+			t.burn()
+			street := t.deck.PopMulti(n)
+			t.record(Event{Type: BoardDealt, Cards: street})
+			board = append(board, street...)
+		}
+		boards = append(boards, board)
+	}
+	return boards
+}
+
+// burn discards the top card of the deck before a street of the board is
+// dealt.
+func (t *Table) burn() {
+	t.record(Event{Type: CardBurned, Cards: []hand.Card{t.deck.Pop()}})
+}
+
+// comparer returns a function comparing players' hands on the board with
+// the Evaluator option if set and by their hands otherwise.
+func (t *Table) comparer(hands map[*Player]*hand.Hand, board []hand.Card) func(a, b *Player) int {
+	if e := t.options.Evaluator; e != nil {
+		return func(a, b *Player) int {
+			aCards := append(append([]hand.Card{}, a.Cards...), board...)
+			bCards := append(append([]hand.Card{}, b.Cards...), board...)
+			return e.Compare(aCards, bCards)
+		}
+	}
+	return func(a, b *Player) int {
+		return hands[a].CompareTo(hands[b])
+	}
+}
+
+// bestHands returns the players with the best hand, more than one if
+// they tie.
+func bestHands(contesting []*Player, compare func(a, b *Player) int) []*Player {
+	winners := []*Player{}
+	for _, seat := range contesting {
+		if len(winners) == 0 {
+			winners = append(winners, seat)
+			continue
+		}
+		switch c := compare(seat, winners[0]); {
+		case c > 0:
+			winners = []*Player{seat}
+		case c == 0:
+			winners = append(winners, seat)
+		}
+	}
+	return winners
+}
 
-func (p *Player) contribute(chips int) {
+// show adds the cards of the players who show to the result.  At a
+// showdown everyone still in the hand shows in showdown order unless they
+// muck, or with AutoMuck are beaten by a hand already shown, except that
+// winners must show to win.  Otherwise only players who choose to show
+// do.
+func (t *Table) show(result *Result, hands map[*Player]*hand.Hand) {
+	result.Shown = map[string][]hand.Card{}
+	for _, seat := range t.seats {
+		if seat.Shown && !seat.SittingOut {
+			result.Shown[seat.ID] = append([]hand.Card(nil), seat.Cards...)
+		}
+	}
+	if len(t.contesting()) < 2 {
+		return
+	}
+	winners := []string{}
+	for _, pot := range result.Pots {
+		winners = union(winners, pot.Winners)
+		winners = union(winners, pot.LowWinners)
+	}
+	compare := t.comparer(hands, result.Board)
+	shown := []*Player{}
+	var best *Player
+	for _, seat := range t.showdownOrder() {
+		beaten := t.options.AutoMuck && best != nil && compare(seat, best) < 0
+		if (seat.Mucked || beaten) && !seat.Shown && !includesID(winners, seat.ID) {
+			continue
+		}
+		result.Shown[seat.ID] = append([]hand.Card(nil), seat.Cards...)
+		result.ShowdownOrder = append(result.ShowdownOrder, seat.ID)
+		t.record(Event{Type: Showdown, PlayerID: seat.ID, Cards: result.Shown[seat.ID]})
+		shown = append(shown, seat)
+		if best == nil || compare(seat, best) > 0 {
+			best = seat
+		}
+	}
+	result.ShowdownHands = showdownHands(shown, hands)
+	if info, ok := result.ShowdownHands[result.Pots[0].Winners[0]]; ok {
+		result.WinningHand = &info
+	}
+}
+
+// ShowdownOrder returns the IDs of the players still in the hand in the
+// order they would reveal their cards at showdown.
+func (t *Table) ShowdownOrder() []string {
+	t.mu.Lock()
+	defer t.unlock()
+	ids := []string{}
+	for _, seat := range t.showdownOrder() {
+		ids = append(ids, seat.ID)
+	}
+	return ids
+}
+
+// showdownOrder returns the players still in the hand in the order they
+// reveal at showdown: the last to bet or raise in the final round first
+// and then clockwise.  If no one bet the first to act shows first.
+func (t *Table) showdownOrder() []*Player {
+	first := t.nextInHand(t.button)
+	if t.options.Variant == SevenCardStud {
+		first = t.bestShowing()
+	}
+	if t.aggressor != nil && !t.aggressor.Folded {
+		first = t.aggressor.Seat
+	}
+	order := []*Player{}
+	for i := 0; i < len(t.seats) && first >= 0; i++ {
+		seat := t.seats[(first+i)%len(t.seats)]
+		if !seat.Folded && !seat.SittingOut {
+			order = append(order, seat)
+		}
+	}
+	return order
+}
+
+// showdownHands returns the hands of the players at showdown keyed by
+// player ID.
+func showdownHands(contesting []*Player, hands map[*Player]*hand.Hand) map[string]HandInfo {
+	showdown := map[string]HandInfo{}
+	for _, seat := range contesting {
+		h := hands[seat]
+		// a hand's place is one more than the number of distinct better
+		// hands
+		better := []*hand.Hand{}
+		for _, other := range contesting {
+			o := hands[other]
+			if o.CompareTo(h) <= 0 {
+				continue
+			}
+			distinct := true
+			for _, b := range better {
+				distinct = distinct && b.CompareTo(o) != 0
+			}
+			if distinct {
+				better = append(better, o)
+			}
+		}
+		showdown[seat.ID] = HandInfo{
+			Ranking:     h.Ranking(),
+			Description: h.Description(),
+			Cards:       h.Cards(),
+			Place:       len(better) + 1,
+		}
+	}
+	return showdown
+}
+
+// bestLows returns the players with the best qualifying low, if any.
+func bestLows(contesting []*Player, lows map[*Player]lowHand) []*Player {
+	winners := []*Player{}
+	for _, seat := range contesting {
+		low := lows[seat]
+		if low == nil {
+			continue
+		}
+		if len(winners) == 0 {
+			winners = append(winners, seat)
+			continue
+		}
+		switch c := compareLows(low, lows[winners[0]]); {
+		case c < 0:
+			winners = []*Player{seat}
+		case c == 0:
+			winners = append(winners, seat)
+		}
+	}
+	return winners
+}
+
+// award splits the chips between the winners and returns their IDs.  The
+// chips that don't divide evenly go one each to the winners closest to the
+// left of the button, with the button itself last.
+func (t *Table) award(winners []*Player, chips int, awards *[]Event) []string {
+	sort.Slice(winners, func(i, j int) bool {
+		iDist := t.distanceFromButton(winners[i])
+		jDist := t.distanceFromButton(winners[j])
+		return iDist < jDist
+	})
+	ids := []string{}
+	for i, seat := range winners {
+		share := chips / len(winners)
+		if (chips % len(winners)) > i {
+			share++
+		}
+		seat.Chips += share
+		*awards = append(*awards, Event{Type: PotAwarded, PlayerID: seat.ID, Chips: share})
+		ids = append(ids, seat.ID)
+	}
+	return ids
+}
+
+// rake returns the rake taken from a pot given the rake already taken
+// from earlier pots this hand.
+func (t *Table) rake(chips, raked int) int {
+	rake := int(float64(chips) * t.options.Rake.Percent / 100)
+	if limit := t.options.Rake.Cap; limit > 0 && raked+rake > limit {
+		rake = limit - raked
+	}
+	return rake
+}
+
+// holeCards returns the number of cards dealt to each player, which is
+// the variant's default unless overridden by the options.
+func holeCards(opts Options) int {
+	if opts.HoleCards != 0 {
+		return opts.HoleCards
+	}
+	if isOmaha(opts.Variant) {
+		return 4
+	}
+	if opts.Variant == SevenCardStud {
+		return 3
+	}
+	return 2
+}
+
+// maxSeats returns the most players that can be seated, which is the
+// variant's default unless overridden by the options.
+func maxSeats(opts Options) int {
+	if opts.MaxSeats > 0 {
+		return opts.MaxSeats
+	}
+	switch opts.Variant {
+	case OmahaHi, OmahaHiLo:
+		return 9
+	case ShortDeck:
+		return 6
+	case SevenCardStud:
+		return 7
+	}
+	return 10
+}
+
+// deckFits returns whether every player's hole cards and the board, or
+// both boards when running it twice, can be dealt from one deck along
+// with a burn card before each street of the board.
+func deckFits(opts Options, players int) bool {
+	boards := 1
+	if opts.RunItTwice {
+		boards = 2
+	}
+	if opts.Variant == SevenCardStud {
+		return players*7 <= deckSize(opts.Variant)
+	}
+	return players*holeCards(opts)+8*boards <= deckSize(opts.Variant)
+}
+
+// deckSize returns the number of cards in the variant's deck.
+func deckSize(v Variant) int {
+	if v == ShortDeck {
+		return len(shortDeck(&hand.Deck{Cards: hand.Cards()}).Cards)
+	}
+	return len(hand.Cards())
+}
+
+// shortDeck returns the deck without the twos through fives, keeping the
+// order of the cards left.
+func shortDeck(deck *hand.Deck) *hand.Deck {
+	cards := []hand.Card{}
+	for _, c := range deck.Cards {
+		if c.Rank() >= hand.Six {
+			cards = append(cards, c)
+		}
+	}
+	return &hand.Deck{Cards: cards}
+}
+
+func isOmaha(v Variant) bool {
+	return v == OmahaHi || v == OmahaHiLo
+}
+
+// validHoleCards returns whether the variant can be played with the
+// number of hole cards in the options.  Omaha may be played with four to
+// six hole cards of which exactly two are used.
+func validHoleCards(opts Options) bool {
+	n := holeCards(opts)
+	if isOmaha(opts.Variant) {
+		return n >= 4 && n <= 6
+	}
+	if opts.Variant == SevenCardStud {
+		return n == 3
+	}
+	return n == 2
+}
+
+// handFor returns the best hand the player can make with the board
+// according to the variant's rules.
+func (t *Table) handFor(p *Player, board []hand.Card) *hand.Hand {
+	if isOmaha(t.options.Variant) {
+		return bestOmahaHand(p.Cards, board)
+	}
+	cards := append(append([]hand.Card{}, p.Cards...), board...)
+	if t.options.Variant == ShortDeck {
+		return hand.New(cards, hand.ShortDeck)
+	}
+	return hand.New(cards)
+}
+
+type sidePot struct {
+	contesting []*Player
+	chips      int
+}
+
+func (t *Table) pots() []*sidePot {
+	contesting := t.contesting()
+	sort.Slice(contesting, func(i, j int) bool {
+		return contesting[i].ChipsInPot < contesting[j].ChipsInPot
+	})
+	costs := []int{}
+	for _, seat := range contesting {
+		if contains(costs, seat.ChipsInPot) == false {
+			costs = append(costs, seat.ChipsInPot)
+		}
+	}
+	pots := []*sidePot{}
+	for i, cost := range costs {
+		pot := &sidePot{}
+		min := 0
+		if i != 0 {
+			min = costs[i-1]
+		}
+		// each pot holds the chips between its cost and the previous one,
+		// with anything above the last cost going to the last pot
+		for _, seat := range t.seats {
+			chips := seat.ChipsInPot
+			if i != len(costs)-1 && chips > cost {
+				chips = cost
+			}
+			pot.chips += max(chips-min, 0)
+			// antes and dead blinds don't count toward calls so are all
+			// in the main pot
+			if i == 0 {
+				pot.chips += seat.DeadChips
+			}
+		}
+		for _, seat := range contesting {
+			if seat.ChipsInPot >= cost {
+				pot.contesting = append(pot.contesting, seat)
+			}
+		}
+		pots = append(pots, pot)
+	}
+	return pots
+}
+
+func (t *Table) resetAction() {
+	for _, seat := range t.seats {
+		seat.Acted = false
+		seat.Capped = false
+	}
+}
+
+// nextSeat returns the next seat after the given seat that is dealt into
+// the hand or -1 if there isn't one.
+func (t *Table) nextSeat(seat int) int {
+	for i := 1; i <= len(t.seats); i++ {
+		next := (seat + i) % len(t.seats)
+		if !t.seats[next].SittingOut {
+			return next
+		}
+	}
+	return -1
+}
+
+// firstToAct returns the first seat from the given one whose player can
+// still bet, so isn't all in, or the given seat if no one can, as when
+// the board is run out.
+func (t *Table) firstToAct(seat int) int {
+	for i := 0; i < len(t.seats); i++ {
+		next := (seat + i) % len(t.seats)
+		if p := t.seats[next]; !p.SittingOut && !p.Folded && !p.AllIn {
+			return next
+		}
+	}
+	return seat
+}
+
+// nextInHand returns the next seat after the given seat whose player
+// hasn't folded or -1 if there isn't one.
+func (t *Table) nextInHand(seat int) int {
+	for i := 1; i <= len(t.seats); i++ {
+		next := (seat + i) % len(t.seats)
+		if p := t.seats[next]; !p.SittingOut && !p.Folded {
+			return next
+		}
+	}
+	return -1
+}
+
+// nextToAct returns the next seat after the active player that still
+// needs to act or -1 if the round is over.
+func (t *Table) nextToAct() int {
+	for i := 1; i < len(t.seats); i++ {
+		p := t.seats[(t.active.Seat+i)%len(t.seats)]
+		if !p.SittingOut && !p.Acted && !p.AllIn && !p.Folded {
+			return p.Seat
+		}
+	}
+	return -1
+}
+
+// minRaise returns the smallest bet or raise allowed under the game's
+// limit.
+func (t *Table) minRaise() int {
+	return t.betLimit().minRaise(t)
+}
+
+// maxRaise returns the most the active player may bet or raise after
+// calling, which is their stack or the game's limit if smaller.
+func (t *Table) maxRaise() int {
+	if t.owed() > t.active.Chips {
+		return 0
+	}
+	chips := t.active.Chips - t.owed()
+	if limit := t.betLimit().maxRaise(t); chips > limit {
+		return limit
+	}
+	return chips
+}
+
+// raiseBounds returns the smallest and largest bet or raise the active
+// player may make.  A player short of a full raise may only go all in, so
+// both are what they have left after calling.
+func (t *Table) raiseBounds() (int, int) {
+	minRaise, maxRaise := t.minRaise(), t.maxRaise()
+	if minRaise > maxRaise {
+		minRaise = maxRaise
+	}
+	return minRaise, maxRaise
+}
+
+// maxPotRaise returns the largest bet or raise allowed under pot limit,
+// which is the size of the pot after the active player calls.
+func (t *Table) maxPotRaise() int {
+	pot := 0
+	for _, seat := range t.seats {
+		pot += seat.ChipsInPot + seat.DeadChips
+	}
+	return pot + t.owed()
+}
+
+// dealtIn returns the number of players who aren't sitting out.
+func (t *Table) dealtIn() int {
+	count := 0
+	for _, seat := range t.seats {
+		if !seat.SittingOut {
+			count++
+		}
+	}
+	return count
+}
+
+// headsUp returns whether only two players are dealt into the hand.
+func (t *Table) headsUp() bool {
+	return t.dealtIn() == 2
+}
+
+// owed returns the chips the active player must put in to call.
+func (t *Table) owed() int {
+	return t.owedBy(t.active)
+}
+
+// owedBy returns the chips the player must put in to call.  A player
+// facing only players all in for less than the cost, such as a big blind
+// all in for less than the blind, only has to match the most any of them
+// put in.  Players all in owe nothing.
+func (t *Table) owedBy(p *Player) int {
+	if p.AllIn {
+		return 0
+	}
+	cost := 0
+	for _, seat := range t.contesting() {
+		if seat == p {
+			continue
+		}
+		if !seat.AllIn {
+			return t.cost - p.ChipsInPot
+		}
+		cost = max(cost, seat.ChipsInPot)
+	}
+	if cost > t.cost {
+		cost = t.cost
+	}
+	return max(cost-p.ChipsInPot, 0)
+}
+
+// potOdds returns the call's share of the pot after calling.
+func potOdds(call, pot int) float64 {
+	if call == 0 {
+		return 0
+	}
+	return float64(call) / float64(pot+call)
+}
+
+// callAmount returns the chips the active player puts in to call.
+func (t *Table) callAmount() int {
+	owed := t.owed()
+	if owed < 0 {
+		return 0
+	}
+	if owed > t.active.Chips {
+		return t.active.Chips
+	}
+	return owed
+}
+
+func (t *Table) distanceFromButton(p *Player) int {
+	dist := (p.Seat - t.button + len(t.seats)) % len(t.seats)
+	if dist == 0 {
+		dist = len(t.seats)
+	}
+	return dist
+}
+
+func (t *Table) player(id string) *Player {
+	for _, seat := range t.seats {
+		if seat.ID == id {
+			return seat
+		}
+	}
+	return nil
+}
+
+// allIn returns whether no more betting is possible because at most one
+// player contesting the hand has chips behind.
+func (t *Table) allIn() bool {
+	chips := 0
+	for _, seat := range t.contesting() {
+		if !seat.AllIn {
+			chips++
+		}
+	}
+	return chips <= 1
+}
+
+func (t *Table) contesting() []*Player {
+	contesting := []*Player{}
+	for _, seat := range t.seats {
+		if seat.Folded == false && seat.SittingOut == false {
+			contesting = append(contesting, seat)
+		}
+	}
+	return contesting
+}
+
+// Player is a seat at the table.  Capped is set when the player has to
+// act again only because of an all in for less than a full raise, or
+// once betting is capped under fixed limit, so they may call or fold but
+// not raise.
+type Player struct {
+	ID         string
+	Seat       int
+	Chips      int
+	ChipsInPot int
+	Acted      bool
+	Capped     bool
+	Folded     bool
+	AllIn      bool
+	SittingOut bool
+	Leaving    bool
+	MustPost   bool
+	Mucked     bool
+	Shown      bool
+	Defaulting bool
+	Cards      []hand.Card
+	// UpCards are the cards in Cards dealt face up in seven card stud
+	// which every player can see
+	UpCards []hand.Card
+	// Away is set for players sitting out by choice and MissedBlinds is
+	// the number of times the big blind has passed them since
+	Away         bool
+	MissedBlinds int
+	// Entering is set for players added to the table until they are first
+	// dealt in
+	Entering bool
+	// DeadChips are chips in the pot from antes and dead blinds which
+	// don't count toward the player's calls
+	DeadChips int
+}
+
+func (p *Player) contribute(chips int) int {
 	amount := chips
 	if p.Chips <= amount {
 		amount = p.Chips
@@ -438,6 +2231,20 @@ func (p *Player) contribute(chips int) {
 	}
 	p.ChipsInPot += amount
 	p.Chips -= amount
+	return amount
+}
+
+// postDead puts chips in the pot as a dead blind and returns the amount
+// posted.
+func (p *Player) postDead(chips int) int {
+	amount := chips
+	if p.Chips <= amount {
+		amount = p.Chips
+		p.AllIn = true
+	}
+	p.DeadChips += amount
+	p.Chips -= amount
+	return amount
 }
 
 func includes(actions []ActionType, include ...ActionType) bool {
@@ -460,6 +2267,29 @@ func max(i, j int) int {
 	return j
 }
 
+// union returns a with any strings in b it doesn't already contain.
+func union(a, b []string) []string {
+	for _, s := range b {
+		found := false
+		for _, v := range a {
+			found = found || s == v
+		}
+		if !found {
+			a = append(a, s)
+		}
+	}
+	return a
+}
+
+func includesID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
 func contains(a []int, i int) bool {
 	for _, v := range a {
 		if v == i {
@@ -468,3 +2298,12 @@ func contains(a []int, i int) bool {
 	}
 	return false
 }
+
+func containsCard(cards []hand.Card, c hand.Card) bool {
+	for _, v := range cards {
+		if v == c {
+			return true
+		}
+	}
+	return false
+}
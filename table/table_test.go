@@ -1,10 +1,21 @@
 package table_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
 	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/notnil/joker/hand"
+	"github.com/notnil/joker/jokertest"
 	"github.com/notnil/joker/table"
 )
 
@@ -50,20 +61,3646 @@ var (
 			},
 			description: "full hand 1",
 		},
+		{
+			start:   threePerson100Buyin(),
+			actions: nil,
+			condition: func(s table.State) bool {
+				return s.MinRaise == 2 && s.MaxRaise == 98
+			},
+			description: "preflop raise bounds",
+		},
+		{
+			start: threePerson100Buyin(),
+			actions: []table.Action{
+				{table.Raise, 5},
+			},
+			condition: func(s table.State) bool {
+				return s.MinRaise == 5 && s.MaxRaise == 93
+			},
+			description: "preflop raise bounds facing a raise",
+		},
+		{
+			start: threePerson100Buyin(),
+			actions: []table.Action{
+				{table.Raise, 5},
+				{table.Call, 0},
+				{table.Fold, 0},
+			},
+			condition: func(s table.State) bool {
+				return s.Round == table.Flop && s.MinRaise == 2 && s.MaxRaise == 93
+			},
+			description: "flop raise bounds",
+		},
+		{
+			start:   threePersonPotLimit(),
+			actions: nil,
+			condition: func(s table.State) bool {
+				return s.MinRaise == 2 && s.MaxRaise == 5
+			},
+			description: "pot limit preflop raise bounds",
+		},
+		{
+			start: threePersonPotLimit(),
+			actions: []table.Action{
+				{table.Raise, 5},
+				{table.Call, 0},
+				{table.Fold, 0},
+			},
+			condition: func(s table.State) bool {
+				return s.Round == table.Flop && s.MinRaise == 2 && s.MaxRaise == 16
+			},
+			description: "pot limit flop raise bounds",
+		},
+		{
+			start: scripted(table.TexasHoldem, []string{"a", "b"},
+				"Js", "3h", "7h", "7c", "2c", "As", "Ks", "Qs", "3c", "7s", "4c", "2d"),
+			actions: checkDown,
+			condition: func(s table.State) bool {
+				return chips(s.Seats[0]) == 102 && chips(s.Seats[1]) == 98
+			},
+			description: "holdem flush beats trips",
+		},
+		{
+			start: scripted(table.OmahaHi, []string{"a", "b"},
+				"Js", "3h", "4d", "8c", "7h", "7c", "9d", "Tc", "2c", "As", "Ks", "Qs", "3c", "7s", "4c", "2d"),
+			actions: checkDown,
+			condition: func(s table.State) bool {
+				return chips(s.Seats[0]) == 98 && chips(s.Seats[1]) == 102
+			},
+			description: "omaha requires two hole cards for the flush",
+		},
+	}
+
+	// checkDown calls the big blind heads up and checks every street
+	checkDown = []table.Action{
+		{table.Call, 0}, {table.Check, 0},
+		{table.Check, 0}, {table.Check, 0},
+		{table.Check, 0}, {table.Check, 0},
+		{table.Check, 0}, {table.Check, 0},
+	}
+)
+
+func TestTable(t *testing.T) {
+	for _, tc := range testCases {
+		tbl := tc.start
+		for _, a := range tc.actions {
+			if err := tbl.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if tc.condition(tbl.State()) == false {
+			t.Fatalf(tc.description)
+		}
+	}
+}
+
+func TestBetFraction(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"}, "As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s")
+	for _, a := range []table.Action{{table.Call, 0}, {table.Call, 0}, {table.Check, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tbl.BetFraction(0); err == nil {
+		t.Fatal("expected an error betting none of the pot")
+	}
+	steps := []struct {
+		bet  func() error
+		cost int
+	}{
+		// half the pot of 6 on top of the 2 each put in before the flop
+		{tbl.BetHalfPot, 5},
+		// the pot of 12 after calling 3 on top of the call
+		{tbl.BetPot, 17},
+		// a tenth of the pot is less than the minimum raise of 12
+		{func() error { return tbl.BetFraction(0.1) }, 29},
+		// ten times the pot is more than c has left
+		{func() error { return tbl.BetFraction(10) }, 100},
+	}
+	for i, step := range steps {
+		if err := step.bet(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if cost := tbl.State().Cost; cost != step.cost {
+			t.Fatalf("step %d: expected a cost of %d but got %d", i, step.cost, cost)
+		}
+	}
+
+	// pot limit caps the bet at the pot
+	tbl = threePersonPotLimit()
+	if err := tbl.BetFraction(3); err != nil {
+		t.Fatal(err)
+	}
+	if cost := tbl.State().Cost; cost != 7 {
+		t.Fatalf("expected a pot sized raise to 7 but got %d", cost)
+	}
+}
+
+func TestRaiseChips(t *testing.T) {
+	tbl := threePerson100Buyin()
+	// a raise of 5 facing the big blind of 2 puts in 7
+	if err := tbl.Raise(5); err != nil {
+		t.Fatal(err)
+	}
+	s := tbl.State()
+	if b := s.Seats[1]; b.ChipsInPot != 7 || b.Chips != 93 || s.Cost != 7 {
+		t.Fatalf("expected b to raise by 5 to 7 but got %+v with cost %d", b, s.Cost)
+	}
+	if events := tbl.History(); events[len(events)-1].Chips != 7 {
+		t.Fatalf("expected 7 chips to be recorded but got %+v", events[len(events)-1])
+	}
+	// c has 1 in the pot so a raise of 5 puts in 11 to make it 12
+	if err := tbl.Raise(5); err != nil {
+		t.Fatal(err)
+	}
+	if c := tbl.State().Seats[2]; c.ChipsInPot != 12 || c.Chips != 88 {
+		t.Fatalf("expected c to raise by 5 to 12 but got %+v", c)
+	}
+}
+
+func TestMinRaise(t *testing.T) {
+	tbl := threePerson100Buyin()
+	if err := tbl.Raise(5); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Raise(4); err == nil {
+		t.Fatal("expected re-raise of 4 to be smaller than the last raise of 5")
+	}
+	if s := tbl.State(); s.MinRaise != 5 {
+		t.Fatalf("expected min raise of 5 but got %d", s.MinRaise)
+	}
+	if err := tbl.Raise(5); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Cost != 12 || s.MinRaise != 5 {
+		t.Fatalf("expected cost of 12 and min raise of 5 but got %d and %d", s.Cost, s.MinRaise)
+	}
+}
+
+func TestShortRaiseBounds(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"}, "As", "Ks", "Qs", "Js")
+	// a has 15 chips behind the big blind, leaving 5 after calling b's
+	// raise of 10 which is short of raising by 10 again
+	snapshot := tbl.Snapshot()
+	snapshot.Seats[0].Chips = 15
+	snapshot.Chips -= 83
+	tbl = table.Restore(hand.NewDealer(rand.New(rand.NewSource(0))), snapshot)
+	if err := tbl.Raise(10); err != nil {
+		t.Fatal(err)
+	}
+	s := tbl.State()
+	if s.Active.ID != "a" || s.MinRaise != 5 || s.MaxRaise != 5 {
+		t.Fatalf("expected a to be able to raise all in by 5 but got %d to %d", s.MinRaise, s.MaxRaise)
+	}
+	for _, l := range tbl.LegalActionsDetailed() {
+		if l.Type == table.Raise && (l.Min != s.MinRaise || l.Max != s.MaxRaise) {
+			t.Fatalf("expected the legal raise to match the state but got %d to %d", l.Min, l.Max)
+		}
+	}
+}
+
+func TestOmahaDeal(t *testing.T) {
+	tbl := scripted(table.OmahaHi, []string{"a", "b", "c"}, "As", "Ks", "Qs", "Js", "Ts", "9s", "8s",
+		"7s", "6s", "5s", "4s", "3s", "2s", "Ah", "Kh", "Qh", "Jh")
+	for _, seat := range tbl.State().Seats {
+		if len(seat.Cards) != 4 {
+			t.Fatalf("expected 4 hole cards but got %d", len(seat.Cards))
+		}
+	}
+	ids := []string{}
+	for i := 0; i < 12; i++ {
+		ids = append(ids, string(rune('a'+i)))
+	}
+	r := rand.New(rand.NewSource(0))
+	opts := table.Options{Variant: table.OmahaHi, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Buyin: 100}
+	if s := table.New(hand.NewDealer(r), opts, ids).State(); s.Status != table.Broken {
+		t.Fatalf("expected twelve omaha players to need too many cards but got status %v", s.Status)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"}, "As", "Ks", "Qs", "Js")
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []table.Event{
+		{Type: table.HandStarted, PlayerID: "b"},
+		{Type: table.BlindPosted, PlayerID: "b", Chips: 1},
+		{Type: table.BlindPosted, PlayerID: "a", Chips: 2},
+		{Type: table.CardsDealt, PlayerID: "a"},
+		{Type: table.CardsDealt, PlayerID: "b"},
+		{Type: table.ActionTaken, PlayerID: "b", Action: table.Fold},
+		// the uncalled half of a's big blind is returned to them
+		{Type: table.BetReturned, PlayerID: "a", Chips: 1},
+		{Type: table.PotAwarded, PlayerID: "a", Chips: 2},
+	}
+	history := tbl.LastHistory()
+	if len(history) != len(expected) {
+		t.Fatalf("expected %d events but got %d", len(expected), len(history))
+	}
+	for i, e := range expected {
+		actual := history[i]
+		if actual.Type != e.Type || actual.PlayerID != e.PlayerID || actual.Action != e.Action || actual.Chips != e.Chips {
+			t.Fatalf("expected event %d to be %+v but got %+v", i, e, actual)
+		}
+	}
+	if cards := history[3].Cards; len(cards) != 2 || cards[0] != hand.AceSpades {
+		t.Fatalf("expected a to be dealt A♠ K♠ but got %v", cards)
+	}
+	if h := tbl.History(); len(h) != 5 || h[0].Type != table.HandStarted || h[1].PlayerID != "a" || h[1].Type != table.BlindPosted {
+		t.Fatalf("expected the next hand's history to start with a's small blind but got %+v", h)
+	}
+}
+
+func TestBurnCards(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"},
+		"As", "Ks", "Qs", "Js", "2c", "Ts", "9s", "8s", "3c", "7s", "4c", "6s")
+	for _, a := range checkDown {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	burned := []hand.Card{}
+	for i, e := range tbl.LastHistory() {
+		if e.Type != table.CardBurned {
+			continue
+		}
+		burned = append(burned, e.Cards...)
+		if next := tbl.LastHistory()[i+1]; next.Type != table.BoardDealt {
+			t.Fatalf("expected the board to be dealt after a burn but got %+v", next)
+		}
+	}
+	if expected := jokertest.Cards("2c", "3c", "4c"); !reflect.DeepEqual(burned, expected) {
+		t.Fatalf("expected %v to be burned but got %v", expected, burned)
+	}
+	if board := tbl.State().Result.Board; !reflect.DeepEqual(board, jokertest.Cards("Ts", "9s", "8s", "7s", "6s")) {
+		t.Fatalf("expected the burned cards to be left off the board but got %v", board)
+	}
+}
+
+func TestDeckFingerprint(t *testing.T) {
+	opts := table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}}
+	a := table.New(hand.NewSeededDealer(7), opts, []string{"a", "b"})
+	b := table.New(hand.NewSeededDealer(7), opts, []string{"a", "b"})
+	expected := hand.NewSeededDealer(7).Deck().Fingerprint()
+	if a.DeckFingerprint() != expected || b.DeckFingerprint() != expected {
+		t.Fatalf("expected fingerprint %s but got %s and %s", expected, a.DeckFingerprint(), b.DeckFingerprint())
+	}
+	if err := a.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	if a.DeckFingerprint() == expected {
+		t.Fatal("expected the next hand to be dealt from a new deck")
+	}
+}
+
+func TestCommitShuffle(t *testing.T) {
+	opts := table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}}
+	tbl := table.New(hand.NewCommitDealer(), opts, []string{"a", "b"})
+	commitment := tbl.CommitShuffle()
+	if seed, deck := tbl.RevealShuffle(); commitment == "" || seed != "" || deck != nil {
+		t.Fatalf("expected a commitment without revealing the shuffle but got %q, %q and %v", commitment, seed, deck)
+	}
+	dealt := tbl.State().Seats[0].Cards
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	seed, deck := tbl.RevealShuffle()
+	if !(hand.Shuffle{Seed: seed, Deck: deck}).Verify(commitment) {
+		t.Fatalf("expected the shuffle %q of %v to verify", seed, deck)
+	}
+	if !reflect.DeepEqual(dealt, []hand.Card{deck[len(deck)-1], deck[len(deck)-2]}) {
+		t.Fatalf("expected a to have been dealt %v from the top of %v", dealt, deck)
+	}
+	if next := tbl.CommitShuffle(); next == "" || next == commitment {
+		t.Fatalf("expected a new commitment for the next hand but got %q", next)
+	}
+	if c := table.New(hand.NewDealer(rand.New(rand.NewSource(0))), opts, []string{"a", "b"}).CommitShuffle(); c != "" {
+		t.Fatalf("expected no commitment from a dealer that doesn't make them but got %q", c)
+	}
+}
+
+func TestPlayerStats(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"}, "As", "Ks", "Qs", "Js", "Ts", "9s", "2c", "8s", "7s", "6s", "3c", "5s", "4c", "4s")
+	actions := []table.Action{
+		// b raises to 6, c calls from the small blind and a folds
+		{table.Raise, 4}, {table.Call, 0}, {table.Fold, 0},
+		// c checks and calls b's bet on the flop
+		{table.Check, 0}, {table.Bet, 10}, {table.Call, 0},
+	}
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := map[string]table.PlayerStats{
+		"a": {Forced: 2, Rounds: map[table.Round]int{}},
+		"b": {Rounds: map[table.Round]int{table.PreFlop: 6, table.Flop: 10}, Voluntary: true, PreFlopRaise: true},
+		"c": {Forced: 1, Rounds: map[table.Round]int{table.PreFlop: 5, table.Flop: 10}, Voluntary: true},
+	}
+	for id, stats := range expected {
+		if actual := tbl.PlayerStats(id); !reflect.DeepEqual(actual, stats) {
+			t.Fatalf("expected %s's stats to be %+v but got %+v", id, stats, actual)
+		}
+	}
+	// the stats are kept for the last hand once it's over
+	for tbl.State().Result == nil {
+		if err := tbl.Check(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := tbl.LastPlayerStats("b"); !reflect.DeepEqual(stats, expected["b"]) {
+		t.Fatalf("expected b's stats for the last hand to be %+v but got %+v", expected["b"], stats)
+	}
+	if stats := tbl.PlayerStats("b"); stats.Voluntary || len(stats.Rounds) != 0 {
+		t.Fatalf("expected b's stats to start over for the next hand but got %+v", stats)
+	}
+
+	// going all in counts as a raise only for more than the call
+	tbl = scripted(table.TexasHoldem, []string{"a", "b", "c"}, "As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s")
+	for _, a := range []table.Action{{table.AllIn, 0}, {table.AllIn, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b, c := tbl.PlayerStats("b"), tbl.PlayerStats("c"); !b.PreFlopRaise || c.PreFlopRaise || !c.Voluntary {
+		t.Fatalf("expected b's shove to be a raise and c's to be a call but got %+v and %+v", b, c)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	opts := table.Options{
+		Variant: table.TexasHoldem,
+		Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:   100,
+		Logger:  log.New(buf, "", 0),
+	}
+	tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(42))), opts, []string{"a", "b", "c"})
+	if err := tbl.Act(table.Action{Type: table.Call}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Act(table.Action{Type: table.Fold}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "b calls\nc folds\n"; buf.String() != expected {
+		t.Fatalf("expected %q to be logged but got %q", expected, buf.String())
+	}
+	// a logger may use the table as the line is logged after releasing it
+	var tbl2 *table.Table
+	active := []string{}
+	opts.Logger = logFunc(func(format string, v ...interface{}) {
+		active = append(active, tbl2.State().Active.ID)
+	})
+	tbl2 = table.New(hand.NewDealer(rand.New(rand.NewSource(42))), opts, []string{"a", "b", "c"})
+	if err := tbl2.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(active, []string{"c"}) {
+		t.Fatalf("expected c to be active when b's call was logged but got %v", active)
+	}
+}
+
+// logFunc is a Logger calling the function.
+type logFunc func(format string, v ...interface{})
+
+func (f logFunc) Printf(format string, v ...interface{}) {
+	f(format, v...)
+}
+
+func BenchmarkAct(b *testing.B) {
+	benchmarkAct(b, nil)
+}
+
+func BenchmarkActLogged(b *testing.B) {
+	benchmarkAct(b, log.New(ioutil.Discard, "", log.LstdFlags))
+}
+
+// benchmarkAct has the button fold every hand heads up so the chips go
+// back and forth without either player going broke.
+func benchmarkAct(b *testing.B, logger table.Logger) {
+	opts := table.Options{
+		Variant: table.TexasHoldem,
+		Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:   100,
+		Logger:  logger,
+	}
+	tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(42))), opts, []string{"a", "b"})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := tbl.Act(table.Action{Type: table.Fold}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPayout plays hands at a full table where half the players
+// fold before the flop and the rest check down to a showdown.
+func BenchmarkPayout(b *testing.B) {
+	opts := table.Options{
+		Variant: table.TexasHoldem,
+		Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:   1000000,
+	}
+	ids := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(42))), opts, ids)
+	actions := []table.ActionType{table.Fold, table.Fold, table.Fold, table.Fold, table.Fold, table.Call, table.Call, table.Call, table.Call, table.Check}
+	for i := 0; i < 15; i++ {
+		actions = append(actions, table.Check)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, a := range actions {
+			if err := tbl.Act(table.Action{Type: a}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestOnEvent(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"}, "As", "Ks", "Qs", "Js", "2c", "Ts", "9s", "8s", "3c", "7s", "4c", "6s")
+	events := []table.Event{}
+	rounds := []table.Round{}
+	tbl.OnEvent(func(e table.Event) {
+		events = append(events, e)
+		// handlers can use the table
+		rounds = append(rounds, tbl.State().Round)
+	})
+	for _, a := range checkDown {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []table.Event{
+		{Type: table.ActionTaken, PlayerID: "b", Action: table.Call, Chips: 1},
+		{Type: table.ActionTaken, PlayerID: "a", Action: table.Check},
+		{Type: table.CardBurned, Round: table.Flop},
+		{Type: table.BoardDealt, Round: table.Flop},
+		{Type: table.ActionTaken, PlayerID: "a", Action: table.Check, Round: table.Flop},
+		{Type: table.ActionTaken, PlayerID: "b", Action: table.Check, Round: table.Flop},
+		{Type: table.CardBurned, Round: table.Turn},
+		{Type: table.BoardDealt, Round: table.Turn},
+		{Type: table.ActionTaken, PlayerID: "a", Action: table.Check, Round: table.Turn},
+		{Type: table.ActionTaken, PlayerID: "b", Action: table.Check, Round: table.Turn},
+		{Type: table.CardBurned, Round: table.River},
+		{Type: table.BoardDealt, Round: table.River},
+		{Type: table.ActionTaken, PlayerID: "a", Action: table.Check, Round: table.River},
+		{Type: table.ActionTaken, PlayerID: "b", Action: table.Check, Round: table.River},
+		// with no bet on the river a shows first and b's queen high
+		// straight wins
+		{Type: table.Showdown, PlayerID: "a", Round: table.River},
+		{Type: table.Showdown, PlayerID: "b", Round: table.River},
+		{Type: table.PotAwarded, PlayerID: "b", Chips: 4, Round: table.River},
+		// the next hand starts with a on the button
+		{Type: table.HandStarted, PlayerID: "a"},
+		{Type: table.BlindPosted, PlayerID: "a", Chips: 1},
+		{Type: table.BlindPosted, PlayerID: "b", Chips: 2},
+		{Type: table.CardsDealt, PlayerID: "a"},
+		{Type: table.CardsDealt, PlayerID: "b"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events but got %+v", len(expected), events)
+	}
+	for i, e := range expected {
+		actual := events[i]
+		if actual.Type != e.Type || actual.PlayerID != e.PlayerID || actual.Action != e.Action || actual.Chips != e.Chips || actual.Round != e.Round {
+			t.Fatalf("expected event %d to be %+v but got %+v", i, e, actual)
+		}
+	}
+	// each handler sees the table after the action that recorded the event
+	if rounds[0] != table.PreFlop || rounds[1] != table.Flop || rounds[len(rounds)-1] != table.PreFlop {
+		t.Fatalf("expected the handler to see the table after each action but got rounds %v", rounds)
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	tbl := threePerson100Buyin()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tbl.State()
+				tbl.History()
+				// another goroutine may act first so errors are expected
+				legal := tbl.LegalActions()
+				tbl.Act(table.Action{Type: legal[1]})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRemovePlayer(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c", "d"},
+		"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s")
+	if err := tbl.RemovePlayer("c"); err == nil {
+		t.Fatal("expected removing a player in the hand to fail")
+	}
+	for _, a := range []table.Action{{table.Call, 0}, {table.Fold, 0}, {table.Fold, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tbl.RemovePlayer("d"); err == nil {
+		t.Fatal("expected removing the active player to fail")
+	}
+	if err := tbl.RemovePlayer("c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	s := tbl.State()
+	if len(s.Seats) != 3 {
+		t.Fatalf("expected 3 seats but got %d", len(s.Seats))
+	}
+	for i, id := range []string{"a", "b", "d"} {
+		if s.Seats[i].ID != id || s.Seats[i].Seat != i {
+			t.Fatalf("expected %s in seat %d but got %s in seat %d", id, i, s.Seats[i].ID, s.Seats[i].Seat)
+		}
+	}
+	// the big blind moves on to a and d posts the small blind, leaving
+	// the button dead in c's old seat after b who acts first
+	if !s.DeadButton || s.Button != 1 || s.Active.ID != "b" {
+		t.Fatalf("expected a dead button with b to act but got button %d and %s to act", s.Button, s.Active.ID)
+	}
+	if s.Seats[0].ChipsInPot != 2 || s.Seats[2].ChipsInPot != 1 || chips(s.Seats[0]) != 103 {
+		t.Fatalf("expected a to post the big blind and d the small blind but got %+v", s.Seats)
+	}
+}
+
+func TestRemovePlayerWaiting(t *testing.T) {
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s", "3s", "2s"}
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"}, cards...)
+	for _, id := range []string{"b", "c"} {
+		if err := tbl.SitOut(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for tbl.State().Status == table.Dealing {
+		if err := tbl.Fold(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// with no hand in progress b and c leave straight away with their chips
+	for _, id := range []string{"b", "c"} {
+		if err := tbl.RemovePlayer(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := tbl.State(); len(s.Seats) != 1 || s.Seats[0].ID != "a" {
+		t.Fatalf("expected only a to be seated but got %+v", s.Seats)
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.AddPlayer("d"); err != nil {
+		t.Fatal(err)
+	}
+	s := tbl.State()
+	if s.Status != table.Dealing || len(s.Seats) != 2 || s.Seats[1].ID != "d" || len(s.Seats[1].Cards) != 2 {
+		t.Fatalf("expected a hand to be dealt to a and d but got %v", s)
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeadButton(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e", "f"}
+	tbl := scripted(table.TexasHoldem, ids,
+		"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s", "3s", "2s", "Ah", "Kh", "Qh", "Jh")
+	// the small blind leaves in these hands
+	sbLeaves := map[int]bool{1: true, 4: true, 8: true}
+	left := map[string]bool{}
+	lastBB, lastSB := "", ""
+	for hand := 0; hand < 12; hand++ {
+		blinds := []table.Event{}
+		for _, e := range tbl.History() {
+			if e.Type == table.BlindPosted {
+				blinds = append(blinds, e)
+			}
+		}
+		bb, sb := blinds[1].PlayerID, blinds[0].PlayerID
+		if bb == lastBB {
+			t.Fatalf("hand %d: expected %s not to post the big blind twice in a row", hand, bb)
+		}
+		// the big blind goes to the next player still seated
+		if lastBB != "" {
+			i := strings.Index(strings.Join(ids, ""), lastBB)
+			expected := ""
+			for j := 1; expected == ""; j++ {
+				if id := ids[(i+j)%len(ids)]; !left[id] {
+					expected = id
+				}
+			}
+			if bb != expected || sb != lastBB {
+				t.Fatalf("hand %d: expected blinds %s and %s but got %s and %s", hand, lastBB, expected, sb, bb)
+			}
+			// the button is dead when the last small blind has left
+			if s := tbl.State(); s.DeadButton != left[lastSB] {
+				t.Fatalf("hand %d: expected dead button %v but got %v", hand, left[lastSB], s.DeadButton)
+			}
+		}
+		lastBB, lastSB = bb, sb
+		// everyone folds to the big blind except when the small blind
+		// leaves, which it can only do with the hand still going, so the
+		// first player raises and folds to the big blind's raise
+		result := tbl.State().Result
+		raises := 0
+		for tbl.State().Result == result {
+			id := tbl.Active().ID
+			action := tbl.Fold
+			if sbLeaves[hand] && (raises == 0 || raises == 1 && id == bb) {
+				raises++
+				action = func() error { return tbl.Raise(2) }
+			}
+			if err := action(); err != nil {
+				t.Fatal(err)
+			}
+			if id == sb && sbLeaves[hand] {
+				if err := tbl.RemovePlayer(id); err != nil {
+					t.Fatal(err)
+				}
+				left[id] = true
+			}
+		}
+	}
+	if len(tbl.State().Seats) != 3 {
+		t.Fatalf("expected three players left but got %+v", tbl.State().Seats)
+	}
+}
+
+func TestDeadSmallBlind(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c", "d"},
+		"As", "Ad", "7c", "2d", "8c", "3d", "Kc", "Qd", "2c", "Ah", "9s", "6h", "3c", "4c", "5c", "Jd")
+	// d in the big blind calls a's shove and busts
+	for _, a := range []table.Action{{table.AllIn, 0}, {table.Fold, 0}, {table.Fold, 0}, {table.Call, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the big blind moves on to a and the small blind is dead while c
+	// keeps the button
+	blinds := []table.Event{}
+	for _, e := range tbl.History() {
+		if e.Type == table.BlindPosted {
+			blinds = append(blinds, e)
+		}
+	}
+	if len(blinds) != 1 || blinds[0].PlayerID != "a" {
+		t.Fatalf("expected only a to post the big blind but got %+v", blinds)
+	}
+	if s := tbl.State(); s.Button != 2 || s.DeadButton || s.Active.ID != "b" {
+		t.Fatalf("expected c on the button and b to act but got button %d and %s to act", s.Button, s.Active.ID)
+	}
+}
+
+func TestSitOut(t *testing.T) {
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s", "3s", "2s"}
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c", "d"}, cards...)
+	// c sits out mid hand but plays out the hand in progress
+	if err := tbl.SitOut("c"); err != nil {
+		t.Fatal(err)
+	}
+	if c := tbl.State().Seats[2]; c.SittingOut || len(c.Cards) != 2 {
+		t.Fatalf("expected c to stay in the hand in progress but got %+v", c)
+	}
+	for tbl.State().Active.ID != "c" {
+		if err := tbl.Call(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	result := tbl.State().Result
+	for tbl.State().Result == result {
+		if err := tbl.Fold(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c := tbl.State().Seats[2]; !c.SittingOut || len(c.Cards) != 0 {
+		t.Fatalf("expected c to be dealt out of the next hand but got %+v", c)
+	}
+	// c returns for the hand after sitting in
+	if err := tbl.SitIn("c"); err != nil {
+		t.Fatal(err)
+	}
+	if c := tbl.State().Seats[2]; !c.SittingOut {
+		t.Fatalf("expected c to wait for the next hand but got %+v", c)
+	}
+	result = tbl.State().Result
+	for tbl.State().Result == result {
+		if err := tbl.Fold(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c := tbl.State().Seats[2]; c.SittingOut || len(c.Cards) != 2 {
+		t.Fatalf("expected c to be dealt back in but got %+v", c)
+	}
+}
+
+func TestSitOutHeadsUp(t *testing.T) {
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s", "3s", "2s"}
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"}, cards...)
+	if err := tbl.SitOut("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Status != table.Waiting || tbl.Active() != nil {
+		t.Fatalf("expected the table to wait for b with no one to act but got %v", s.Status)
+	}
+	// the next hand is dealt as soon as b sits back in
+	if err := tbl.SitIn("b"); err != nil {
+		t.Fatal(err)
+	}
+	s := tbl.State()
+	if s.Status != table.Dealing || s.Active.ID == "" {
+		t.Fatalf("expected a hand to be dealt but got %v", s.Status)
+	}
+	for _, seat := range s.Seats {
+		if seat.SittingOut || len(seat.Cards) != 2 {
+			t.Fatalf("expected %s to be dealt in but got %+v", seat.ID, seat)
+		}
+	}
+}
+
+func TestSetButton(t *testing.T) {
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s", "3s", "2s"}
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c", "d"}, cards...)
+	foldHand := func() {
+		result := tbl.State().Result
+		for tbl.State().Result == result {
+			if err := tbl.Fold(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, test := range []struct {
+		button     int
+		sb, bb     int
+		firstToAct string
+	}{
+		{3, 0, 1, "c"},
+		{0, 1, 2, "d"},
+		{2, 3, 0, "b"},
+	} {
+		if err := tbl.SetButton(test.button); err != nil {
+			t.Fatal(err)
+		}
+		foldHand()
+		s := tbl.State()
+		if s.Button != test.button || s.Seats[test.sb].ChipsInPot != 1 || s.Seats[test.bb].ChipsInPot != 2 || s.Active.ID != test.firstToAct {
+			t.Fatalf("expected the button on seat %d with %s to act but got %+v", test.button, test.firstToAct, s)
+		}
+	}
+	if err := tbl.SetButton(4); err == nil {
+		t.Fatal("expected an error moving the button to an empty seat")
+	}
+	if err := tbl.SitOut("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.SetButton(1); err == nil {
+		t.Fatal("expected an error moving the button to a player sitting out")
+	}
+}
+
+func TestMissedBlinds(t *testing.T) {
+	opts := table.Options{
+		Stakes:           table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:            100,
+		PostMissedBlinds: true,
+	}
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s", "3s", "2s"}
+	// everyone folds to the big blind
+	foldHand := func(tbl *table.Table) {
+		result := tbl.State().Result
+		for tbl.State().Result == result {
+			if err := tbl.Fold(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, post := range []bool{true, false} {
+		tbl := scriptedWith(opts, []string{"a", "b", "c", "d"}, cards...)
+		// a sits out the second hand in which the big blind passes them
+		if err := tbl.SitOut("a"); err != nil {
+			t.Fatal(err)
+		}
+		foldHand(tbl)
+		if a := tbl.State().Seats[0]; !a.SittingOut || a.MissedBlinds != 1 {
+			t.Fatalf("expected a to sit out having missed the big blind but got %+v", a)
+		}
+		if err := tbl.SitIn("a"); err != nil {
+			t.Fatal(err)
+		}
+		foldHand(tbl)
+		if a := tbl.State().Seats[0]; !a.SittingOut || len(a.Cards) != 0 {
+			t.Fatalf("expected a to wait to come back in but got %+v", a)
+		}
+		if post {
+			if err := tbl.PostMissedBlind("a"); err != nil {
+				t.Fatal(err)
+			}
+			foldHand(tbl)
+			s := tbl.State()
+			a := s.Seats[0]
+			if a.SittingOut || a.ChipsInPot != 2 || a.DeadChips != 1 || a.MissedBlinds != 0 || s.Pot != 6 {
+				t.Fatalf("expected a to post a live big blind and dead small blind but got %+v", a)
+			}
+			if s.Active.ID != "a" || !includes(tbl.LegalActions(), table.Check) {
+				t.Fatalf("expected a to act first with the option to check but got %s", s.Active.ID)
+			}
+			continue
+		}
+		// waiting a comes back in when the big blind reaches them
+		foldHand(tbl)
+		if a := tbl.State().Seats[0]; !a.SittingOut {
+			t.Fatalf("expected a to still be waiting but got %+v", a)
+		}
+		foldHand(tbl)
+		if a := tbl.State().Seats[0]; a.SittingOut || a.ChipsInPot != 2 || a.DeadChips != 0 || a.MissedBlinds != 0 {
+			t.Fatalf("expected a to come back in the big blind but got %+v", a)
+		}
+	}
+	tbl := scriptedWith(opts, []string{"a", "b", "c"}, cards...)
+	if err := tbl.PostMissedBlind("a"); err == nil {
+		t.Fatal("expected an error posting blinds that weren't missed")
+	}
+}
+
+func TestChipsConserved(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		opts := table.Options{
+			Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2},
+			Buyin:   20 + r.Intn(100),
+			Variant: table.Variant(r.Intn(3)),
+			Limit:   table.Limit(r.Intn(2)),
+			Rake:    table.Rake{Percent: float64(r.Intn(10)), Cap: r.Intn(5)},
+		}
+		ids := []string{"a", "b", "c", "d", "e", "f"}[:2+r.Intn(5)]
+		tbl := table.New(hand.NewDealer(r), opts, ids)
+		for i := 0; i < 500 && tbl.State().Status == table.Dealing; i++ {
+			s := tbl.State()
+			legal := tbl.LegalActions()
+			a := table.Action{Type: legal[r.Intn(len(legal))]}
+			if a.Type == table.Bet || a.Type == table.Raise {
+				if s.MinRaise == 0 {
+					continue
+				}
+				a.Chips = s.MinRaise + r.Intn(s.MaxRaise-s.MinRaise+1)
+			}
+			// players come and go between hands
+			if r.Intn(50) == 0 {
+				tbl.AddPlayer(string(rune('g' + i%20)))
+			}
+			if r.Intn(50) == 0 {
+				tbl.RemovePlayer(ids[r.Intn(len(ids))])
+			}
+			if err := tbl.Act(a); err != nil {
+				t.Fatalf("seed %d: %v", seed, err)
+			}
+			if err := tbl.Verify(); err != nil {
+				t.Fatalf("seed %d: %v", seed, err)
+			}
+		}
+	}
+}
+
+func TestPlayRandom(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		opts := table.Options{
+			Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2, BringIn: 1},
+			Buyin:   100,
+			Variant: table.Variant(r.Intn(5)),
+			Limit:   table.Limit(r.Intn(2)),
+		}
+		ids := []string{"a", "b", "c", "d", "e", "f"}[:2+r.Intn(5)]
+		tbl := table.New(hand.NewDealer(r), opts, ids)
+		states := tbl.PlayRandom(r)
+		for _, s := range states {
+			total := 0
+			for _, seat := range s.Seats {
+				total += chips(seat) + seat.DeadChips
+			}
+			if total != len(ids)*opts.Buyin {
+				t.Fatalf("seed %d: expected %d chips on the table but got %d", seed, len(ids)*opts.Buyin, total)
+			}
+			if s.Status == table.Dealing && s.Active.AllIn {
+				t.Fatalf("seed %d: expected a player all in never to act but got\n%v", seed, s)
+			}
+		}
+		if last := states[len(states)-1]; last.Status != table.Waiting {
+			t.Fatalf("seed %d: expected the game to be played out but got %v", seed, last.Status)
+		}
+	}
+}
+
+func TestAutoPlayer(t *testing.T) {
+	// junk picks any action with any chips, legal or not
+	junk := func(r *rand.Rand) table.Strategy {
+		return func(s table.State, legal []table.LegalAction) table.Action {
+			return table.Action{Type: table.ActionType(r.Intn(7)), Chips: r.Intn(300) - 50}
+		}
+	}
+	for seed := int64(0); seed < 100; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		opts := table.Options{
+			Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2, BringIn: 1},
+			Buyin:   100,
+			Variant: table.Variant(r.Intn(5)),
+			Limit:   table.Limit(r.Intn(3)),
+		}
+		ids := []string{"a", "b", "c", "d", "e", "f"}[:2+r.Intn(5)]
+		tbl := table.New(hand.NewDealer(r), opts, ids)
+		strategies := []table.Strategy{nil, table.CallingStation, table.Tight, junk(r)}
+		for i := 0; i < 300 && tbl.State().Status == table.Dealing; i++ {
+			s := tbl.State()
+			p := table.AutoPlayer{Strategy: strategies[r.Intn(len(strategies))]}
+			a := p.Choose(tbl.StateFor(s.Active.ID), tbl.LegalActionsDetailed())
+			if err := tbl.CanAct(a); err != nil {
+				t.Fatalf("seed %d: expected %v to be legal but got %v\n%v", seed, a, err, s)
+			}
+			if err := p.Act(tbl); err != nil {
+				t.Fatalf("seed %d: %v", seed, err)
+			}
+		}
+	}
+
+	tbl := threePerson100Buyin()
+	if err := (table.AutoPlayer{}).Act(tbl); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Active.ID != "c" || s.Seats[1].ChipsInPot != 2 {
+		t.Fatalf("expected the calling station to call but got\n%v", s)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	opts := table.Options{
+		Variant: table.TexasHoldem,
+		Limit:   table.NoLimit,
+		Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:   100,
+	}
+	deal := func() hand.Dealer {
+		return jokertest.Dealer(jokertest.Cards("As", "Ks", "Qs", "Js", "Ts", "9s", "2c", "8s", "7s", "6s", "3c", "5s", "4c", "4s"))
+	}
+	actions := []table.Action{
+		// b raises to 6, c calls from the small blind and a folds
+		{table.Raise, 4}, {table.Call, 0}, {table.Fold, 0},
+		// c check calls b's bet on the flop and both check it down
+		{table.Check, 0}, {table.Bet, 10}, {table.Call, 0},
+		{table.Check, 0}, {table.Check, 0},
+		{table.Check, 0}, {table.Check, 0},
+	}
+	states, err := table.Replay(deal(), opts, []string{"a", "b", "c"}, actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != len(actions) {
+		t.Fatalf("expected a state after each of the %d actions but got %d", len(actions), len(states))
+	}
+	if s := states[3]; s.Round != table.Flop || s.Pot != 14 || s.Active.ID != "b" {
+		t.Fatalf("expected b to act on a pot of 14 after c checks the flop but got\n%v", s)
+	}
+	// c's ten high straight flush beats the eight high one on the board
+	last := states[len(states)-1]
+	if last.Result == nil || !reflect.DeepEqual(last.Result.Winners, []string{"c"}) {
+		t.Fatalf("expected c to win the hand but got\n%v", last)
+	}
+	for id, expected := range map[string]int{"a": 98, "b": 84, "c": 118} {
+		for _, seat := range last.Seats {
+			if seat.ID == id && chips(seat) != expected {
+				t.Fatalf("expected %s to finish the hand with %d chips but got %d", id, expected, chips(seat))
+			}
+		}
+	}
+
+	// the states up to an illegal action are returned with the error
+	actions[4] = table.Action{Type: table.Call}
+	states, err = table.Replay(deal(), opts, []string{"a", "b", "c"}, actions)
+	if err == nil || len(states) != 4 {
+		t.Fatalf("expected the call with nothing owed to fail after 4 actions but got %d and %v", len(states), err)
+	}
+}
+
+func TestHeadsUp(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"}, "As", "Ks", "Qs", "Js", "2c", "Ts", "9s", "8s", "3c", "7s", "4c", "6s")
+	for _, button := range []string{"b", "a", "b"} {
+		expectHeadsUp(t, tbl, button)
+		// the button acts last after the flop
+		for _, round := range []table.Round{table.Flop, table.Turn, table.River} {
+			s := tbl.State()
+			if s.Round != round || s.Active.ID == button {
+				t.Fatalf("expected the big blind to act first on the %v but got %s", round, s.Active.ID)
+			}
+			for i := 0; i < 2; i++ {
+				if err := tbl.Check(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+
+	// c busts leaving a heads up with b
+	tbl = scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ad", "7c", "2d", "Kc", "Qd", "2c", "Ah", "9s", "6h", "3c", "4c", "5c", "Jd")
+	for _, a := range []table.Action{{table.Fold, 0}, {table.AllIn, 0}, {table.Call, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c := tbl.State().Seats[2]; !c.SittingOut {
+		t.Fatalf("expected c to bust but got %+v", c)
+	}
+	expectHeadsUp(t, tbl, "a")
+	if s := tbl.State(); s.Round != table.Flop || s.Active.ID != "b" {
+		t.Fatalf("expected b to act first on the flop but got %s", s.Active.ID)
+	}
+}
+
+// expectHeadsUp checks the button posts the small blind and acts first
+// before the flop then calls and checks to the flop.
+func expectHeadsUp(t *testing.T, tbl *table.Table, button string) {
+	t.Helper()
+	blinds := []table.Event{}
+	for _, e := range tbl.History() {
+		if e.Type == table.BlindPosted {
+			blinds = append(blinds, e)
+		}
+	}
+	if len(blinds) != 2 || blinds[0].PlayerID != button || blinds[0].Chips != 1 || blinds[1].PlayerID == button {
+		t.Fatalf("expected %s on the button to post the small blind but got %+v", button, blinds)
+	}
+	s := tbl.State()
+	if s.Seats[s.Button].ID != button || s.Active.ID != button {
+		t.Fatalf("expected %s on the button to act first but got %s", button, s.Active.ID)
+	}
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Check(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShortAllIn(t *testing.T) {
+	tests := []struct {
+		raise  int
+		capped bool
+	}{
+		// c's shove of 40 more is less than b's raise of 58
+		{58, true},
+		// c's shove is a full raise of b's raise of 2
+		{2, false},
+	}
+	for _, test := range tests {
+		tbl := threePerson100Buyin()
+		if err := tbl.Raise(test.raise); err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.AllIn(); err != nil {
+			t.Fatal(err)
+		}
+		// a hasn't acted so isn't capped, though with only the call left
+		// a can't raise and may only shove for it
+		if legal := tbl.LegalActions(); !includes(legal, table.AllIn) || includes(legal, table.Raise) {
+			t.Fatalf("expected a to be able to go all in but not raise but got %v", legal)
+		}
+		if err := tbl.Fold(); err != nil {
+			t.Fatal(err)
+		}
+		// b's call is all b has left, so b can only shove if not capped
+		legal := tbl.LegalActions()
+		if includes(legal, table.Raise) || test.capped != !includes(legal, table.AllIn) {
+			t.Fatalf("expected capped %v but b can %v", test.capped, legal)
+		}
+		if test.capped {
+			if err := tbl.AllIn(); err == nil {
+				t.Fatal("expected b not to be able to reraise a short all in")
+			}
+		}
+		if err := tbl.Call(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestZeroRaise(t *testing.T) {
+	// b on the button only has 12 chips
+	snapshot := threePerson100Buyin().Snapshot()
+	snapshot.Seats[1].Chips = 12
+	snapshot.Chips -= 88
+	tbl := table.Restore(hand.NewDealer(rand.New(rand.NewSource(0))), snapshot)
+	// everyone limps and on the flop b's call of c's bet puts b all in
+	actions := []table.Action{
+		{table.Call, 0}, {table.Call, 0}, {table.Check, 0},
+		{table.Bet, 10}, {table.Call, 0},
+	}
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// b has nothing left to raise with, so raising by nothing is refused
+	// rather than leaving c and a unable to check
+	for _, l := range tbl.LegalActionsDetailed() {
+		if l.Type == table.Raise {
+			t.Fatalf("expected b not to be offered a raise but got %v", l)
+		}
+	}
+	if err := tbl.Raise(0); err == nil {
+		t.Fatal("expected a raise of nothing to be refused")
+	}
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Round != table.Turn || !includes(tbl.LegalActions(), table.Check) {
+		t.Fatalf("expected c to be able to check the turn but got %v\n%v", tbl.LegalActions(), s)
+	}
+}
+
+func TestRake(t *testing.T) {
+	deck := []string{"As", "Ad", "2c", "7d", "3c", "Kh", "9s", "5c", "4c", "3d", "6c", "Jh"}
+	raised := append([]table.Action{{table.Raise, 18}, {table.Call, 0}}, checkDown[2:]...)
+	tests := []struct {
+		rake    table.Rake
+		actions []table.Action
+		raked   int
+	}{
+		{table.Rake{Percent: 10}, raised, 4},
+		{table.Rake{Percent: 10, Cap: 3}, raised, 3},
+		{table.Rake{Percent: 10}, []table.Action{{table.Raise, 18}, {table.Fold, 0}}, 0},
+	}
+	for _, test := range tests {
+		opts := table.Options{
+			Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2},
+			Buyin:  100,
+			Rake:   test.rake,
+		}
+		tbl := scriptedWith(opts, []string{"a", "b"}, deck...)
+		for _, a := range test.actions {
+			if err := tbl.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s := tbl.State()
+		if s.Result == nil || s.Result.Raked != test.raked {
+			t.Fatalf("expected %d chips raked but got %+v", test.raked, s.Result)
+		}
+		if total := chips(s.Seats[0]) + chips(s.Seats[1]); total != 200-test.raked {
+			t.Fatalf("expected %d chips on the table but got %d", 200-test.raked, total)
+		}
+	}
+}
+
+func TestBlindSchedule(t *testing.T) {
+	opts := table.Options{
+		Buyin: 1000,
+		BlindSchedule: []table.Stakes{
+			{SmallBlind: 1, BigBlind: 2},
+			{SmallBlind: 2, BigBlind: 4},
+			{SmallBlind: 5, BigBlind: 10},
+		},
+		HandsPerLevel: 2,
+	}
+	tbl := scriptedWith(opts, []string{"a", "b"}, "As", "Ks", "Qs", "Js")
+	levels := []int{0, 0, 1, 1, 2, 2, 2}
+	blinds := []int{3, 3, 6, 6, 15, 15, 15}
+	for i := range levels {
+		s := tbl.State()
+		if tbl.CurrentLevel() != levels[i] || s.Pot != blinds[i] {
+			t.Fatalf("hand %d: expected level %d with %d in blinds but got level %d with %d",
+				i, levels[i], blinds[i], tbl.CurrentLevel(), s.Pot)
+		}
+		if err := tbl.Fold(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tbl.AdvanceBlindLevel()
+	if tbl.CurrentLevel() != 2 {
+		t.Fatalf("expected to stay on the last level but got %d", tbl.CurrentLevel())
+	}
+
+	opts.HandsPerLevel = 0
+	tbl = scriptedWith(opts, []string{"a", "b"}, "As", "Ks", "Qs", "Js")
+	tbl.AdvanceBlindLevel()
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); tbl.CurrentLevel() != 1 || s.Pot != 6 {
+		t.Fatalf("expected level 1 with 6 in blinds but got level %d with %d", tbl.CurrentLevel(), s.Pot)
+	}
+}
+
+func TestUncalledBet(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"}, "As", "Ks", "Qs", "Js", "Ts", "9s", "2c", "8s", "7s", "6s", "3c", "5s", "4c", "4s")
+	// everyone limps and checks to the river where c bets 50 into 6
+	actions := []table.Action{{table.Call, 0}, {table.Call, 0}, {table.Check, 0}}
+	for i := 0; i < 6; i++ {
+		actions = append(actions, table.Action{Type: table.Check})
+	}
+	actions = append(actions, table.Action{Type: table.Bet, Chips: 50}, table.Action{Type: table.Fold}, table.Action{Type: table.Fold})
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	won := 0
+	for _, e := range tbl.LastHistory() {
+		if e.Type == table.PotAwarded {
+			won += e.Chips
+		}
+	}
+	s := tbl.State()
+	if won != 6 || len(s.Result.Pots) != 1 || s.Result.Pots[0].Chips != 6 {
+		t.Fatalf("expected c to win only the pot of 6 but got %d\n%v", won, s.Result)
+	}
+	if s.Result.Returned != 50 || s.Result.ReturnedTo != "c" {
+		t.Fatalf("expected the uncalled 50 to be returned to c but got %d to %q", s.Result.Returned, s.Result.ReturnedTo)
+	}
+	if !strings.Contains(s.Result.String(), "returned: 50 to c") {
+		t.Fatalf("expected the returned chips in the result but got\n%v", s.Result)
+	}
+	// the chips moved by the events account for every stack
+	stacks := map[string]int{"a": 100, "b": 100, "c": 100}
+	for _, e := range tbl.LastHistory() {
+		switch e.Type {
+		case table.AntePosted, table.BlindPosted, table.StraddlePosted, table.BringInPosted, table.ActionTaken:
+			stacks[e.PlayerID] -= e.Chips
+		case table.BetReturned, table.PotAwarded:
+			stacks[e.PlayerID] += e.Chips
+		}
+	}
+	for _, seat := range s.Seats {
+		expected := map[string]int{"a": 98, "b": 98, "c": 104}[seat.ID]
+		if chips(seat) != expected || stacks[seat.ID] != expected {
+			t.Fatalf("expected %s to have %d chips but got %d with %d from the events", seat.ID, expected, chips(seat), stacks[seat.ID])
+		}
+	}
+	if stats := tbl.LastPlayerStats("c"); stats.Rounds[table.River] != 0 {
+		t.Fatalf("expected c's uncalled bet not to count but got %d on the river", stats.Rounds[table.River])
+	}
+}
+
+func TestSidePots(t *testing.T) {
+	s := playSidePots(t)
+	if s.Result == nil || len(s.Result.Board) != 5 {
+		t.Fatal("expected the hand to reach a showdown")
+	}
+	total := 0
+	for _, seat := range s.Seats {
+		total += chips(seat)
+	}
+	if total != 400 {
+		t.Fatalf("expected 400 chips on the table but got %d", total)
+	}
+	expected := []table.PotResult{
+		{Contesting: []string{"a", "c", "d"}, Chips: 299},
+		{Contesting: []string{"a", "d"}, Chips: 2},
+	}
+	if len(s.Result.Pots) != len(expected) {
+		t.Fatalf("expected %d pots but got %+v", len(expected), s.Result.Pots)
+	}
+	for i, pot := range s.Result.Pots {
+		contesting := append([]string{}, pot.Contesting...)
+		sort.Strings(contesting)
+		if pot.Chips != expected[i].Chips || !reflect.DeepEqual(contesting, expected[i].Contesting) {
+			t.Fatalf("expected pot %d to be %+v but got %+v", i, expected[i], pot)
+		}
+	}
+	if s.Result.Returned != 1 || s.Result.ReturnedTo != "d" {
+		t.Fatalf("expected d's uncalled chip to be returned but got %d to %q", s.Result.Returned, s.Result.ReturnedTo)
+	}
+	if !reflect.DeepEqual(s.Result.Winners, s.Result.Pots[0].Winners) {
+		t.Fatalf("expected winners %v to be the main pot winners %v", s.Result.Winners, s.Result.Pots[0].Winners)
+	}
+}
+
+func TestMainPotWinner(t *testing.T) {
+	s := playSidePots(t)
+	// a's wheel wins the main pot and the side pot d called
+	if !reflect.DeepEqual(s.Result.Winners, []string{"a"}) {
+		t.Fatalf("expected a to win the main pot but got %v", s.Result.Winners)
+	}
+	if last := s.Result.Pots[len(s.Result.Pots)-1]; !reflect.DeepEqual(last.Winners, []string{"a"}) {
+		t.Fatalf("expected a to win the last side pot but got %v", last.Winners)
+	}
+}
+
+// playSidePots plays two hands at a four handed table, the second of which
+// has three players all in for different amounts and a folded caller.
+func playSidePots(t *testing.T) table.State {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c", "d"},
+		"As", "Ks", "Qh", "Jh", "Td", "9d", "8c", "7c", "2c", "2s", "3h", "4d", "3c", "5c", "4c", "Kd")
+	actions := []table.Action{
+		// c posts the small blind and folds to d's big blind
+		{table.Fold, 0}, {table.Fold, 0}, {table.Fold, 0},
+		// b limps and folds after c, d, and a are all in for 99, 101, and 100
+		{table.Call, 0}, {table.AllIn, 0}, {table.AllIn, 0}, {table.Call, 0}, {table.Fold, 0},
+	}
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tbl.State()
+}
+
+func TestPotEligibility(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c", "d"},
+		"As", "Ks", "Qh", "Jh", "Td", "9d", "8c", "7c", "2c", "2s", "3h", "4d", "3c", "5c", "4c", "Kd")
+	actions := []table.Action{
+		{table.Fold, 0}, {table.Fold, 0}, {table.Fold, 0},
+		// b limps and c, d and a are all in for 99, 101 and 100
+		{table.Call, 0}, {table.AllIn, 0}, {table.AllIn, 0}, {table.Call, 0},
+	}
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := map[string][]int{
+		"a": {0, 1, 2},
+		"b": {0},
+		"c": {0, 1},
+		"d": {0, 1, 2, 3},
+	}
+	if eligible := tbl.PotEligibility(); !reflect.DeepEqual(eligible, expected) {
+		t.Fatalf("expected eligibility %v but got %v", expected, eligible)
+	}
+}
+
+func TestShortBigBlind(t *testing.T) {
+	// three handed the others still owe the full big blind
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ad", "Ks", "Kd", "Qh", "Qc", "2c", "2h", "5c", "9d", "3c", "Jh", "4c", "3s")
+	// a takes all but one of b's chips to leave b in the next big blind
+	actions := []table.Action{{table.Raise, 97}, {table.Fold, 0}, {table.Call, 0}}
+	for i := 0; i < 6; i++ {
+		actions = append(actions, table.Action{Type: table.Check})
+	}
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := tbl.State()
+	if b := s.Seats[1]; b.ChipsInPot != 1 || !b.AllIn {
+		t.Fatalf("expected b to be all in for one chip in the big blind but got %+v", b)
+	}
+	if s.Active.ID != "c" || s.CallAmount != 2 {
+		t.Fatalf("expected c to owe the full big blind but got %d for %s", s.CallAmount, s.Active.ID)
+	}
+	for i := 0; i < 8; i++ {
+		s := tbl.State()
+		if s.Active.ID == "b" {
+			t.Fatalf("expected b not to act all in but they are to act on %v", s.Round)
+		}
+		a := table.Action{Type: table.Check}
+		if s.CallAmount > 0 {
+			a.Type = table.Call
+		}
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// heads up the small blind has already matched the big blind
+	tbl = scripted(table.TexasHoldem, []string{"a", "b"},
+		"As", "Ad", "Ks", "Kd", "2c", "2h", "5c", "9d", "3c", "Jh", "4c", "3s")
+	actions = []table.Action{{table.Raise, 97}, {table.Call, 0}}
+	for i := 0; i < 6; i++ {
+		actions = append(actions, table.Action{Type: table.Check})
+	}
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s = tbl.State()
+	if s.Active.ID != "a" || s.CallAmount != 0 || includes(tbl.LegalActions(), table.Call) {
+		t.Fatalf("expected a to owe nothing against b's one chip but got %d", s.CallAmount)
+	}
+	if _, err := tbl.RunOut(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLastPlayerWithChips(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ad", "2c", "7d", "3h", "8s", "3c", "Ks", "Qd", "9c", "4c", "4h", "5c", "5d")
+	actions := []table.Action{
+		{table.AllIn, 0}, {table.AllIn, 0}, {table.AllIn, 0},
+	}
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := tbl.State()
+	if s.Status != table.Waiting {
+		t.Fatalf("expected the table to be done but got %v", s.Status)
+	}
+	if s.Seats[0].Chips != 300 || !s.Seats[1].SittingOut || !s.Seats[2].SittingOut {
+		t.Fatalf("expected a to have all the chips but got %+v", s.Seats)
+	}
+	if err := tbl.Check(); err == nil {
+		t.Fatal("expected acting on a waiting table to fail")
+	}
+	// dealing resumes once b rebuys
+	if err := tbl.BuyPlayerIn("b"); err != nil {
+		t.Fatal(err)
+	}
+	s = tbl.State()
+	if s.Status != table.Dealing || s.Seats[1].SittingOut || !s.Seats[2].SittingOut || len(s.Seats[1].Cards) != 2 {
+		t.Fatalf("expected a hand to be dealt to a and b but got %v", s)
+	}
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFiveCardOmaha(t *testing.T) {
+	opts := table.Options{
+		Variant:   table.OmahaHi,
+		HoleCards: 5,
+		Stakes:    table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:     100,
+	}
+	// a holds a royal flush but can only play two of its cards
+	tbl := scriptedWith(opts, []string{"a", "b"}, "As", "Ks", "Qs", "Js", "Ts",
+		"9c", "9d", "2c", "3c", "4c", "5c", "2s", "3h", "4d", "6c", "8c", "7c", "9h")
+	for _, seat := range tbl.State().Seats {
+		if len(seat.Cards) != 5 {
+			t.Fatalf("expected 5 hole cards but got %d", len(seat.Cards))
+		}
+	}
+	for _, a := range checkDown {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if winners := tbl.State().Result.Winners; !reflect.DeepEqual(winners, []string{"b"}) {
+		t.Fatalf("expected b's three nines to win but got %v", winners)
+	}
+
+	opts.Variant = table.TexasHoldem
+	if tbl := scriptedWith(opts, []string{"a", "b"}); tbl.State().Status != table.Broken {
+		t.Fatal("expected five card hold'em to be rejected")
+	}
+}
+
+func TestOmahaHiLo(t *testing.T) {
+	actions := []table.Action{{table.Call, 0}, {table.Call, 0}}
+	for i := 0; i < 10; i++ {
+		actions = append(actions, table.Action{Type: table.Check})
+	}
+	tests := []struct {
+		board      []string
+		winners    []string
+		lowWinners []string
+		chips      []int
+	}{
+		{[]string{"2s", "5d", "7h", "Kc", "Qd"}, []string{"b"}, []string{"a"}, []int{101, 101, 98}},
+		{[]string{"9s", "Td", "7h", "Kc", "Qd"}, []string{"b"}, nil, []int{98, 104, 98}},
+	}
+	for _, test := range tests {
+		// a card is burned before each street
+		cards := append([]string{"As", "3c", "4h", "6h", "Qc", "Qh", "9c", "9d", "2c", "2h", "3d", "3h", "Jc"}, test.board[:3]...)
+		cards = append(cards, "Jd", test.board[3], "Jh", test.board[4])
+		tbl := scripted(table.OmahaHiLo, []string{"a", "b", "c"}, cards...)
+		for _, a := range actions {
+			if err := tbl.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s := tbl.State()
+		if !reflect.DeepEqual(s.Result.Winners, test.winners) || !reflect.DeepEqual(s.Result.LowWinners, test.lowWinners) {
+			t.Fatalf("expected high %v and low %v but got high %v and low %v",
+				test.winners, test.lowWinners, s.Result.Winners, s.Result.LowWinners)
+		}
+		for i, seat := range s.Seats {
+			if chips(seat) != test.chips[i] {
+				t.Fatalf("expected %s to have %d chips but got %d", seat.ID, test.chips[i], chips(seat))
+			}
+		}
+	}
+}
+
+func TestBetAndRaise(t *testing.T) {
+	tbl := threePerson100Buyin()
+	if err := tbl.Bet(5); err == nil || !strings.Contains(err.Error(), "raise instead") {
+		t.Fatalf("expected bet facing the big blind to fail but got %v", err)
+	}
+	for _, a := range []table.Action{{table.Call, 0}, {table.Call, 0}, {table.Check, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tbl.Raise(5); err == nil || !strings.Contains(err.Error(), "bet instead") {
+		t.Fatalf("expected raise with nothing owed to fail but got %v", err)
+	}
+	if err := tbl.Bet(5); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Bet(10); err == nil {
+		t.Fatal("expected bet facing a bet to fail")
+	}
+	if err := tbl.Raise(10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStraddle(t *testing.T) {
+	opts := table.Options{
+		Stakes:        table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:         100,
+		AllowStraddle: true,
+	}
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s"}
+	tests := []struct {
+		ids     []string
+		active  string
+		last    string
+		cost    int
+		actions int
+	}{
+		// a straddles after d's big blind and b on the button acts first
+		{[]string{"a", "b", "c", "d"}, "b", "a", 4, 3},
+		// three handed b straddles on the button and c in the small blind acts first
+		{[]string{"a", "b", "c"}, "c", "b", 4, 2},
+		// heads up there is no straddle
+		{[]string{"a", "b"}, "b", "a", 2, 1},
+	}
+	for _, test := range tests {
+		tbl := scriptedWith(opts, test.ids, cards...)
+		s := tbl.State()
+		if s.Active.ID != test.active || s.Cost != test.cost {
+			t.Fatalf("expected %s to act facing %d but got %s facing %d", test.active, test.cost, s.Active.ID, s.Cost)
+		}
+		for i := 0; i < test.actions; i++ {
+			if err := tbl.Call(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s = tbl.State()
+		if s.Active.ID != test.last || s.Round != table.PreFlop || !includes(tbl.LegalActions(), table.Check) {
+			t.Fatalf("expected %s to have the option but got %s to act", test.last, s.Active.ID)
+		}
+	}
+}
+
+func TestRunItTwice(t *testing.T) {
+	opts := table.Options{
+		Stakes:     table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:      100,
+		RunItTwice: true,
+	}
+	// a wins the first runout and b makes a set on the second
+	tbl := scriptedWith(opts, []string{"a", "b"},
+		"As", "Ah", "Ks", "Kh",
+		"2d", "2c", "3d", "7h", "3c", "9s", "4d", "Jc",
+		"5c", "Kd", "4c", "5d", "6d", "8h", "6c", "Tc")
+	if err := tbl.AllIn(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	s := tbl.State()
+	if len(s.Result.Boards) != 2 || len(s.Result.Pots) != 2 {
+		t.Fatalf("expected two boards and a pot for each but got %+v", s.Result)
+	}
+	if !reflect.DeepEqual(s.Result.Pots[0].Winners, []string{"a"}) || !reflect.DeepEqual(s.Result.Pots[1].Winners, []string{"b"}) {
+		t.Fatalf("expected a and b to win a runout each but got %+v", s.Result.Pots)
+	}
+	for i, desc := range []string{"pair of aces with jack, nine, seven kickers", "three of a kind kings with ten, eight kickers"} {
+		if h := s.Result.Pots[i].Hand; h == nil || h.String() != desc {
+			t.Fatalf("expected pot %d to be won with %s but got %v", i, desc, h)
+		}
+	}
+	for _, seat := range s.Seats {
+		if chips(seat) != 100 {
+			t.Fatalf("expected %s to have 100 chips but got %d", seat.ID, chips(seat))
+		}
+	}
+
+	for i := int64(0); i < 20; i++ {
+		r := rand.New(rand.NewSource(i))
+		tbl := table.New(hand.NewDealer(r), opts, []string{"a", "b", "c"})
+		for tbl.State().Result == nil {
+			if err := tbl.AllIn(); err != nil && tbl.Call() != nil {
+				t.Fatal(err)
+			}
+		}
+		s := tbl.State()
+		seen := map[hand.Card]bool{}
+		cards := []hand.Card{}
+		for _, e := range tbl.LastHistory() {
+			if e.Type == table.CardsDealt {
+				cards = append(cards, e.Cards...)
+			}
+		}
+		for _, board := range s.Result.Boards {
+			cards = append(cards, board...)
+		}
+		for _, c := range cards {
+			if seen[c] {
+				t.Fatalf("expected %v to be dealt once but the deck was reused", c)
+			}
+			seen[c] = true
+		}
+		total := 0
+		for _, seat := range s.Seats {
+			total += chips(seat)
+		}
+		if total != 300 {
+			t.Fatalf("expected 300 chips on the table but got %d", total)
+		}
+	}
+}
+
+func TestActionTimeout(t *testing.T) {
+	opts := table.Options{
+		Stakes:        table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:         100,
+		ActionTimeout: time.Minute,
+	}
+	tbl := scriptedWith(opts, []string{"a", "b"}, "As", "Ks", "Qs", "Js", "2c", "Ts", "9s", "8s", "3c", "7s", "4c", "6s")
+	// the clock hasn't run out so nothing happens
+	s, err := tbl.CheckTimeout(time.Now())
+	if err != nil || s.Active.ID != "b" || s.Round != table.PreFlop {
+		t.Fatalf("expected b to still be acting but got %s with error %v", s.Active.ID, err)
+	}
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	// a owes nothing in the big blind so checks
+	s, err = tbl.CheckTimeout(time.Now().Add(2 * time.Minute))
+	if err != nil || s.Round != table.Flop {
+		t.Fatalf("expected a to check to the flop but got %v with error %v", s.Round, err)
+	}
+	if err := tbl.Bet(2); err != nil {
+		t.Fatal(err)
+	}
+	// b owes the bet so folds
+	s, err = tbl.CheckTimeout(time.Now().Add(2 * time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Result.Winners, []string{"a"}) {
+		t.Fatalf("expected b to fold to a but got %+v", s.Result)
+	}
+	history := tbl.LastHistory()
+	// the fold is followed by the return of a's bet and the pot
+	if last := history[len(history)-3]; last.PlayerID != "b" || last.Action != table.Fold {
+		t.Fatalf("expected b to fold but got %+v", last)
+	}
+}
+
+func TestEnumStrings(t *testing.T) {
+	tests := []struct {
+		value    fmt.Stringer
+		expected string
+	}{
+		{table.Broken, "Broken"},
+		{table.Dealing, "Dealing"},
+		{table.Waiting, "Waiting"},
+		{table.Status(3), "Status(3)"},
+		{table.PreFlop, "PreFlop"},
+		{table.Flop, "Flop"},
+		{table.Turn, "Turn"},
+		{table.River, "River"},
+		{table.SeventhStreet, "SeventhStreet"},
+		{table.Round(-1), "Round(-1)"},
+		{table.TexasHoldem, "TexasHoldem"},
+		{table.OmahaHi, "OmahaHi"},
+		{table.OmahaHiLo, "OmahaHiLo"},
+		{table.ShortDeck, "ShortDeck"},
+		{table.SevenCardStud, "SevenCardStud"},
+		{table.Variant(5), "Variant(5)"},
+		{table.NoLimit, "NoLimit"},
+		{table.PotLimit, "PotLimit"},
+		{table.FixedLimit, "FixedLimit"},
+		{table.Limit(3), "Limit(3)"},
+	}
+	for _, test := range tests {
+		if s := test.value.String(); s != test.expected {
+			t.Fatalf("expected %s but got %s", test.expected, s)
+		}
+	}
+}
+
+func TestEnumJSON(t *testing.T) {
+	type enums struct {
+		Status  table.Status
+		Round   table.Round
+		Variant table.Variant
+		Limit   table.Limit
+	}
+	v := enums{table.Waiting, table.Flop, table.OmahaHiLo, table.PotLimit}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"Status":"Waiting","Round":"Flop","Variant":"OmahaHiLo","Limit":"PotLimit"}`
+	if string(b) != expected {
+		t.Fatalf("expected %s but got %s", expected, b)
+	}
+	parsed := enums{}
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed != v {
+		t.Fatalf("expected %+v but got %+v", v, parsed)
+	}
+
+	// numbers from before the enums were encoded by name are still accepted
+	parsed = enums{}
+	if err := json.Unmarshal([]byte(`{"Status":2,"Round":1,"Variant":2,"Limit":1}`), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed != v {
+		t.Fatalf("expected %+v but got %+v", v, parsed)
+	}
+
+	bad := []string{`{"Round":"Fourth"}`, `{"Status":true}`, `{"Status":99}`, `{"Round":-1}`}
+	for _, s := range bad {
+		if err := json.Unmarshal([]byte(s), &parsed); err == nil {
+			t.Fatalf("expected an error for %s", s)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		opts  table.Options
+		valid bool
+	}{
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}}, true},
+		{table.Options{Buyin: 2, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}}, true},
+		{table.Options{Buyin: 0, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}}, false},
+		{table.Options{Buyin: 1, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 0, BigBlind: 2}}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: -1, BigBlind: 2}}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 2, BigBlind: 1}}, false},
+		{table.Options{Buyin: 100}, false},
+		{table.Options{Buyin: 100, BlindSchedule: []table.Stakes{{SmallBlind: 1, BigBlind: 2}, {SmallBlind: 0, BigBlind: 4}}}, false},
+		{table.Options{Buyin: 1, BlindSchedule: []table.Stakes{{SmallBlind: 1, BigBlind: 2}}}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, HoleCards: 3}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, MaxStack: 100}, true},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Variant: table.SevenCardStud}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{BigBlind: 2, Ante: 1}, AnteOnly: true}, true},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{BigBlind: 2}, AnteOnly: true}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{BigBlind: 2, BringIn: 1}, Variant: table.SevenCardStud, RunItTwice: true}, false},
+		{table.Options{Buyin: 200, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, MaxStack: 100}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, MaxSeats: 2}, true},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, MaxSeats: 1}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2, Ante: 1}, BigBlindAnte: true}, true},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{BigBlind: 2, Ante: 1}, AnteOnly: true, BigBlindAnte: true}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{BigBlind: 2, Ante: 1}, AnteOnly: true, WaitForBigBlind: true}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, MaxRaisesPerRound: -1}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2, Ante: -1}}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{BigBlind: 2, BringIn: 1, Ante: -1}, Variant: table.SevenCardStud}, false},
+		{table.Options{Buyin: 100, BlindSchedule: []table.Stakes{{SmallBlind: 1, BigBlind: 2}, {SmallBlind: 2, BigBlind: 4, Ante: -1}}}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Rake: table.Rake{Percent: 100}}, true},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Rake: table.Rake{Percent: -5}}, false},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Rake: table.Rake{Percent: 101}}, false},
+	}
+	for i, test := range tests {
+		err := test.opts.Validate()
+		if (err == nil) != test.valid {
+			t.Fatalf("test %d: expected valid %v but got error %v", i, test.valid, err)
+		}
+		tbl := scriptedWith(test.opts, []string{"a", "b"}, "As", "Ks", "Qs", "Js")
+		if (tbl.State().Status != table.Broken) != test.valid || (tbl.Err() == nil) != test.valid {
+			t.Fatalf("test %d: expected valid %v but got status %v with error %v", i, test.valid, tbl.State().Status, tbl.Err())
+		}
+	}
+	tbl := scriptedWith(table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}}, []string{"a"})
+	if tbl.Err() == nil {
+		t.Fatal("expected a table with one player to be broken")
+	}
+}
+
+func TestNewWithError(t *testing.T) {
+	opts := table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}}
+	dealer := hand.NewDealer(rand.New(rand.NewSource(0)))
+	tests := []struct {
+		opts table.Options
+		ids  []string
+		err  string
+	}{
+		{opts, []string{"a"}, "table: at least two players are needed"},
+		{opts, []string{}, "table: at least two players are needed"},
+		{opts, []string{"a", "b", "a"}, "table: player a is already seated"},
+		{table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 0, BigBlind: 2}}, []string{"a", "b"}, "table: small blind of 0 must be positive"},
+		{table.Options{Buyin: 1, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}}, []string{"a", "b"}, "table: buyin of 1 is less than the big blind of 2"},
+	}
+	for i, test := range tests {
+		tbl, err := table.NewWithError(dealer, test.opts, test.ids)
+		if tbl != nil || err == nil || err.Error() != test.err {
+			t.Fatalf("test %d: expected error %q but got %v", i, test.err, err)
+		}
+		tbl = table.New(dealer, test.opts, test.ids)
+		if tbl.State().Status != table.Broken || tbl.Err() == nil || tbl.Err().Error() != test.err {
+			t.Fatalf("test %d: expected broken table with error %q but got %v", i, test.err, tbl.Err())
+		}
+		if actions := tbl.LegalActions(); actions != nil {
+			t.Fatalf("test %d: expected no legal actions at a broken table but got %v", i, actions)
+		}
+	}
+	tbl, err := table.NewWithError(dealer, opts, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tbl.State().Status != table.Dealing {
+		t.Fatalf("expected status %v but got %v", table.Dealing, tbl.State().Status)
+	}
+}
+
+func TestStateJSON(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"}, "As", "Td", "2c", "9h", "3c", "Ks", "Qs", "Js", "4c", "3d", "5c", "4d")
+	for _, a := range []table.Action{{table.Call, 0}, {table.Check, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := json.Marshal(tbl.State())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`"Cards":["Ks","Qs","Js"]`, `"Cards":["As","Td"]`, `"Cards":["2c","9h"]`} {
+		if !strings.Contains(string(b), expected) {
+			t.Fatalf("expected %s in %s", expected, b)
+		}
+	}
+}
+
+func TestRunOut(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ah", "Ks", "Kd", "7c", "2d", "Th", "3h", "8s", "9d", "Td", "Jc", "Tc", "4c")
+	if _, err := tbl.RunOut(); err == nil {
+		t.Fatal("expected an error running out the board before anyone is all in")
+	}
+	if err := tbl.AllIn(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tbl.RunOut(); err == nil {
+		t.Fatal("expected an error running out the board before the all in is called")
+	}
+	// the board is run out as soon as the all in is called
+	for _, a := range []table.Action{{table.Fold, 0}, {table.Call, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := tbl.State()
+	if s.Result == nil || len(s.Result.Board) != 5 || !reflect.DeepEqual(s.Result.Winners, []string{"a"}) {
+		t.Fatalf("expected a to win on a full board but got %+v", s.Result)
+	}
+	if _, err := tbl.RunOut(); err == nil {
+		t.Fatal("expected an error running out the next hand")
+	}
+
+	// heads up b is left with one chip, so the blinds of the next hand put
+	// b all in with a owing nothing and the board waits to be run out
+	play := func() *table.Table {
+		tbl := scripted(table.TexasHoldem, []string{"a", "b"},
+			"As", "Ad", "Ks", "Kd", "2c", "2h", "5c", "9d", "3c", "Jh", "4c", "3s")
+		actions := []table.Action{{table.Raise, 97}, {table.Call, 0}}
+		for i := 0; i < 6; i++ {
+			actions = append(actions, table.Action{Type: table.Check})
+		}
+		for _, a := range actions {
+			if err := tbl.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return tbl
+	}
+	stepped := play()
+	for result := stepped.State().Result; stepped.State().Result == result; {
+		if err := stepped.Check(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := play().RunOut()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := stepped.State()
+	if !reflect.DeepEqual(s.Result, expected.Result) || !reflect.DeepEqual(s.Seats, expected.Seats) {
+		t.Fatalf("expected running out to end as checking through did\n%v\nbut got\n%v", expected, s)
+	}
+	if len(s.Result.Board) != 5 {
+		t.Fatalf("expected the board to be run out but got %v", s.Result.Board)
+	}
+}
+
+func TestAllInSkipped(t *testing.T) {
+	// c in the small blind only has 20 chips
+	snapshot := threePerson100Buyin().Snapshot()
+	snapshot.Seats[2].Chips = 19
+	snapshot.Chips -= 80
+	tbl := table.Restore(hand.NewDealer(rand.New(rand.NewSource(0))), snapshot)
+	// b limps, c shoves and a and b call
+	for _, a := range []table.Action{{table.Call, 0}, {table.AllIn, 0}, {table.Call, 0}, {table.Call, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// c would act first after the flop but is all in so a acts instead
+	if s := tbl.State(); s.Round != table.Flop || s.Active.ID != "a" {
+		t.Fatalf("expected a to act first on the flop but got\n%v", s)
+	}
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Contesting != 2 || s.Seats[2].Folded {
+		t.Fatalf("expected c to stay in the hand but got\n%v", s)
+	}
+}
+
+func TestNoBettingLeft(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ad", "Ks", "Kd", "Qh", "Qc", "2c", "2h", "5c", "9d", "3c", "Jh", "4c", "3s")
+	// a takes the blinds to have the most chips, then calls c's and b's
+	// all ins with chips to spare
+	actions := []table.Action{
+		{table.Fold, 0}, {table.Fold, 0},
+		{table.AllIn, 0}, {table.Call, 0}, {table.AllIn, 0}, {table.Call, 0},
+	}
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := tbl.State()
+	if s.Result == nil || len(s.Result.Board) != 5 || s.Round != table.PreFlop {
+		t.Fatalf("expected the hand to reach a showdown without more action but got %+v", s.Result)
+	}
+	// b and c are out so no hand follows
+	boards := 0
+	for _, e := range tbl.History() {
+		if e.Type == table.ActionTaken && e.Round != table.PreFlop {
+			t.Fatalf("expected no action after the flop but got %+v", e)
+		}
+		if e.Type == table.BoardDealt {
+			boards++
+		}
+	}
+	if boards != 3 {
+		t.Fatalf("expected the flop, turn and river to be dealt but got %d boards", boards)
+	}
+	if a := s.Seats[0]; s.Status != table.Waiting || a.Chips != 300 {
+		t.Fatalf("expected a to win every pot with aces but got %+v", a)
+	}
+}
+
+func TestStateString(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ah", "Ks", "Kd", "7c", "2d", "Th", "3h", "8s", "9d", "Td", "Jc", "Tc", "4c")
+	for _, a := range []table.Action{{table.Call, 0}, {table.Fold, 0}, {table.Check, 0}, {table.Bet, 10}, {table.AllIn, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := tbl.State().String()
+	for _, expected := range []string{"TexasHoldem NoLimit Flop", "board: 3♥ 8♠ 9♦\n", "a to act", "A♠ A♥  to act", "K♠ K♦  all in", "7♣ 2♦  folded", "1 D"} {
+		if !strings.Contains(s, expected) {
+			t.Fatalf("expected %q in\n%s", expected, s)
+		}
+	}
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	s = tbl.State().String()
+	for _, expected := range []string{"last hand:", "board: 3♥ 8♠ 9♦ J♣ 4♣", "201    a", "pair of aces with jack, nine, eight kickers", "winners: a"} {
+		if !strings.Contains(s, expected) {
+			t.Fatalf("expected %q in\n%s", expected, s)
+		}
+	}
+}
+
+func TestScriptedDeal(t *testing.T) {
+	// a card is burned before each street of the board
+	cards, err := hand.ParseCards("As Ah  Ks Kd  7c 2d  Th 3h 8s 9d  Td Jc  Tc 4c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := table.Options{Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Buyin: 100}
+	tbl := table.New(jokertest.Dealer(cards), opts, []string{"a", "b", "c"})
+	if s := tbl.StateFor("a"); !reflect.DeepEqual(s.Seats[0].Cards, []hand.Card{hand.AceSpades, hand.AceHearts}) {
+		t.Fatalf("expected a to have pocket aces but got %v", s.Seats[0].Cards)
+	}
+	for _, a := range []table.Action{{table.AllIn, 0}, {table.Fold, 0}, {table.Call, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the players all in check through to the river
+	for tbl.State().Result == nil {
+		if err := tbl.Act(table.Action{Type: table.Check}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result := tbl.State().Result
+	if !reflect.DeepEqual(result.Winners, []string{"a"}) {
+		t.Fatalf("expected a to win but got %v", result.Winners)
+	}
+	board := []hand.Card{cards[7], cards[8], cards[9], cards[11], cards[13]}
+	if !reflect.DeepEqual(result.Board, board) {
+		t.Fatalf("expected board %v but got %v", board, result.Board)
+	}
+}
+
+func TestShortDeck(t *testing.T) {
+	opts := table.Options{Variant: table.ShortDeck, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Buyin: 100}
+	tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(0))), opts, []string{"a", "b"})
+	for _, seat := range tbl.State().Seats {
+		for _, c := range seat.Cards {
+			if c.Rank() < hand.Six {
+				t.Fatalf("expected no cards below six but %s was dealt %v", seat.ID, seat.Cards)
+			}
+		}
+	}
+
+	// a's flush beats b's full house
+	tbl = scriptedWith(opts, []string{"a", "b"}, "Ah", "Kh", "9c", "9d", "7c", "9h", "6h", "7h", "8c", "6c", "Tc", "Jd")
+	for _, a := range checkDown {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if winners := tbl.State().Result.Winners; !reflect.DeepEqual(winners, []string{"a"}) {
+		t.Fatalf("expected a to win with a flush but got %v", winners)
+	}
+}
+
+func TestSevenCardStud(t *testing.T) {
+	opts := table.Options{
+		Variant: table.SevenCardStud,
+		Stakes:  table.Stakes{BigBlind: 2, BringIn: 1},
+		Buyin:   100,
+	}
+	tbl := scriptedWith(opts, []string{"a", "b", "c"},
+		// third street with the third card face up
+		"As", "Ks", "9h", "Qd", "Jd", "2c", "8c", "7c", "2d",
+		// fourth through sixth street face up and seventh face down
+		"Ah", "3c", "2h",
+		"Kh", "4c", "8h",
+		"9c", "5c", "7h",
+		"3s", "6c", "Th",
+	)
+	if err := tbl.Err(); err != nil {
+		t.Fatal(err)
+	}
+	// b brings in with the two of clubs, the lowest suit
+	s := tbl.StateFor("a")
+	if b := s.Seats[1]; b.ChipsInPot != 1 || !reflect.DeepEqual(b.UpCards, jokertest.Cards("2c")) || b.Cards != nil {
+		t.Fatalf("expected b to bring in showing only the two of clubs but got %+v", b)
+	}
+	if s.Active.ID != "c" {
+		t.Fatalf("expected c to act after the bring in but got %s", s.Active.ID)
+	}
+	for _, a := range []table.Action{{table.Call, 0}, {table.Call, 0}, {table.Check, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the best hand showing acts first on each later street
+	for _, expected := range []struct {
+		round  table.Round
+		active string
+		up     int
+		cards  int
+	}{
+		{table.Flop, "c", 2, 4},
+		{table.Turn, "c", 3, 5},
+		{table.River, "a", 4, 6},
+		{table.SeventhStreet, "a", 4, 7},
+	} {
+		s := tbl.State()
+		if s.Round != expected.round || s.Active.ID != expected.active {
+			t.Fatalf("expected %s to act first on %v but got %s on %v", expected.active, expected.round, s.Active.ID, s.Round)
+		}
+		for _, seat := range s.Seats {
+			if len(seat.UpCards) != expected.up || len(seat.Cards) != expected.cards {
+				t.Fatalf("expected %s to have %d up cards on %v but got %+v", seat.ID, expected.up, s.Round, seat)
+			}
+		}
+		if len(s.Cards) != 0 {
+			t.Fatalf("expected no board but got %v", s.Cards)
+		}
+		for range s.Seats {
+			if err := tbl.Act(table.Action{Type: table.Check}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// b makes a straight flush with their own seven cards
+	if winners := tbl.State().Result.Winners; !reflect.DeepEqual(winners, []string{"b"}) {
+		t.Fatalf("expected b to win but got %v", winners)
+	}
+}
+
+func TestAnteOnly(t *testing.T) {
+	opts := table.Options{
+		Stakes:   table.Stakes{BigBlind: 2, Ante: 1},
+		Buyin:    100,
+		AnteOnly: true,
+	}
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s"}
+	tbl := scriptedWith(opts, []string{"a", "b", "c"}, cards...)
+	if err := tbl.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range tbl.History() {
+		if e.Type == table.BlindPosted {
+			t.Fatalf("expected no blinds but got %+v", e)
+		}
+	}
+	s := tbl.State()
+	for _, seat := range s.Seats {
+		if seat.ChipsInPot != 0 || seat.DeadChips != 1 {
+			t.Fatalf("expected %s to have only anted but got %+v", seat.ID, seat)
+		}
+	}
+	// c is after the button on b
+	if s.Button != 1 || s.Active.ID != "c" {
+		t.Fatalf("expected c to act first after the button but got %s", s.Active.ID)
+	}
+	if actions := tbl.LegalActions(); !includes(actions, table.Check) {
+		t.Fatalf("expected c to be able to check but got %v", actions)
+	}
+	for i := 0; i < 3; i++ {
+		if err := tbl.Act(table.Action{Type: table.Check}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := tbl.State(); s.Round != table.Flop || s.Active.ID != "c" {
+		t.Fatalf("expected c to act first on the flop but got %s on %v", s.Active.ID, s.Round)
+	}
+}
+
+func TestAntes(t *testing.T) {
+	opts := table.Options{
+		Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2, Ante: 1},
+		Buyin:  100,
+	}
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s"}
+	tbl := scriptedWith(opts, []string{"a", "b", "c"}, cards...)
+	if pot := tbl.State().Pot; pot != 6 {
+		t.Fatalf("expected a pot of 6 but got %d", pot)
+	}
+	// b owes the whole big blind despite having anted
+	if err := tbl.Act(table.Action{Type: table.Call}); err != nil {
+		t.Fatal(err)
+	}
+	if b := tbl.State().Seats[1]; b.ChipsInPot != 2 || b.Chips != 97 {
+		t.Fatalf("expected b to call 2 after the ante but got %+v", b)
+	}
+	if err := tbl.Act(table.Action{Type: table.Call}); err != nil {
+		t.Fatal(err)
+	}
+	if c := tbl.State().Seats[2]; c.ChipsInPot != 2 || c.Chips != 97 {
+		t.Fatalf("expected c to complete the small blind but got %+v", c)
+	}
+	// the big blind has the option
+	s := tbl.State()
+	if s.Active.ID != "a" || !includes(tbl.LegalActions(), table.Check) || !includes(tbl.LegalActions(), table.Bet) {
+		t.Fatalf("expected a to have the option to check or bet but got %v", tbl.LegalActions())
+	}
+	if s.Pot != 9 {
+		t.Fatalf("expected a pot of 9 but got %d", s.Pot)
+	}
+}
+
+func TestPendingActors(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c", "d"},
+		"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s", "3s", "2s")
+	steps := []struct {
+		action  table.Action
+		pending []string
+	}{
+		// d has the option in the big blind
+		{table.Action{Type: table.Call}, []string{"b", "c", "d"}},
+		// the raise reopens the action to a
+		{table.Action{Type: table.Raise, Chips: 4}, []string{"c", "d", "a"}},
+		{table.Action{Type: table.Fold}, []string{"d", "a"}},
+		// b is owed action again and d, all in, is left out
+		{table.Action{Type: table.AllIn}, []string{"a", "b"}},
+	}
+	if pending := tbl.PendingActors(); !reflect.DeepEqual(pending, []string{"a", "b", "c", "d"}) {
+		t.Fatalf("expected everyone to be waited on but got %v", pending)
+	}
+	for _, step := range steps {
+		if err := tbl.Act(step.action); err != nil {
+			t.Fatal(err)
+		}
+		if pending := tbl.PendingActors(); !reflect.DeepEqual(pending, step.pending) {
+			t.Fatalf("expected to wait on %v after %v but got %v", step.pending, step.action.Type, pending)
+		}
+	}
+
+	// the active player is left out too if all in
+	snapshot := threePerson100Buyin().Snapshot()
+	snapshot.Seats[snapshot.Active].AllIn = true
+	tbl = table.Restore(hand.NewDealer(rand.New(rand.NewSource(0))), snapshot)
+	if pending := tbl.PendingActors(); !reflect.DeepEqual(pending, []string{"c", "a"}) {
+		t.Fatalf("expected b all in to be left out but got %v", pending)
+	}
+}
+
+func TestEffectiveStack(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"}, "As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s")
+	// b raises and takes the blinds to have more chips than a and c
+	if err := tbl.Raise(8); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"c", "a"} {
+		if err := tbl.Fold(); err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+	}
+	// in the next hand c on the button has 99 and a in the small blind 97
+	tests := []struct {
+		id1, id2 string
+		stack    int
+	}{
+		{"a", "b", 97},
+		{"b", "a", 97},
+		{"b", "c", 99},
+		{"c", "a", 97},
+		{"a", "z", 0},
+	}
+	for _, test := range tests {
+		if stack := tbl.EffectiveStack(test.id1, test.id2); stack != test.stack {
+			t.Fatalf("expected an effective stack of %d between %s and %s but got %d", test.stack, test.id1, test.id2, stack)
+		}
+	}
+}
+
+func TestBigBlindAnte(t *testing.T) {
+	opts := table.Options{
+		Stakes:       table.Stakes{SmallBlind: 1, BigBlind: 2, Ante: 1},
+		Buyin:        100,
+		BigBlindAnte: true,
+	}
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s"}
+	tbl := scriptedWith(opts, []string{"a", "b", "c"}, cards...)
+	// a in the big blind antes for all three players
+	s := tbl.State()
+	for i, expected := range []int{95, 100, 99} {
+		if seat := s.Seats[i]; seat.Chips != expected {
+			t.Fatalf("expected %s to have %d chips but got %d", seat.ID, expected, seat.Chips)
+		}
+	}
+	if a := s.Seats[0]; a.DeadChips != 3 || a.ChipsInPot != 2 || s.Pot != 6 {
+		t.Fatalf("expected a to post a dead ante of 3 with the big blind but got %+v", a)
+	}
+	antes := []table.Event{}
+	for _, e := range tbl.History() {
+		if e.Type == table.AntePosted {
+			antes = append(antes, e)
+		}
+	}
+	if len(antes) != 1 || antes[0].PlayerID != "a" || antes[0].Chips != 3 {
+		t.Fatalf("expected only a to post an ante but got %+v", antes)
+	}
+	// the ante doesn't count toward calling the big blind
+	if s.CallAmount != 2 {
+		t.Fatalf("expected b to call 2 but got %d", s.CallAmount)
+	}
+}
+
+func TestCallAmount(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"}, "As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s")
+	if s := tbl.State(); s.CallAmount != 2 || s.AllInAmount != 100 {
+		t.Fatalf("expected b to call 2 or go all in for 100 but got %d and %d", s.CallAmount, s.AllInAmount)
+	}
+	if err := tbl.Act(table.Action{Type: table.Raise, Chips: 4}); err != nil {
+		t.Fatal(err)
+	}
+	// c has posted the small blind
+	if s := tbl.State(); s.CallAmount != 5 || s.AllInAmount != 99 {
+		t.Fatalf("expected c to call 5 or go all in for 99 but got %d and %d", s.CallAmount, s.AllInAmount)
+	}
+
+	// a call is capped at the stack
+	tbl = scripted(table.TexasHoldem, []string{"a", "b"}, "As", "Ks", "Qs", "Js", "2c", "Ts", "9s", "8s", "3c", "7s", "4c", "6s")
+	if err := tbl.Act(table.Action{Type: table.Fold}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Act(table.Action{Type: table.AllIn}); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Active.ID != "b" || s.CallAmount != 97 || s.AllInAmount != 97 {
+		t.Fatalf("expected b to call 97 for their stack but got %d", s.CallAmount)
+	}
+}
+
+func TestRaiseExceedsStack(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"}, "As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s")
+	// b owes 2 of their 100 chips so can raise at most 98
+	if err := tbl.Act(table.Action{Type: table.Raise, Chips: 99}); err == nil {
+		t.Fatal("expected an error raising more than the stack")
+	}
+	if s := tbl.State(); s.Active.ID != "b" || s.Active.ChipsInPot != 0 {
+		t.Fatalf("expected b to still be to act with nothing in the pot but got %+v", s.Active)
+	}
+	if err := tbl.Act(table.Action{Type: table.Raise, Chips: 98}); err != nil {
+		t.Fatal(err)
+	}
+	if b := tbl.State().Seats[1]; !b.AllIn || b.Chips != 0 {
+		t.Fatalf("expected b to be all in but got %+v", b)
+	}
+	// nor can a player bet more than their stack after the flop
+	tbl = scripted(table.TexasHoldem, []string{"a", "b"}, "As", "Ks", "Qs", "Js", "2c", "Ts", "9s", "8s", "3c", "7s", "4c", "6s")
+	for _, a := range checkDown[:2] {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tbl.Act(table.Action{Type: table.Bet, Chips: 99}); err == nil {
+		t.Fatal("expected an error betting more than the stack")
+	}
+}
+
+func TestLegalActionsDetailed(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"}, "As", "Ks", "Qs", "Js", "2c", "Ts", "9s", "8s", "3c", "7s", "4c", "6s")
+	// b on the button owes 1 to call the big blind
+	expected := []table.LegalAction{
+		{Type: table.Fold},
+		{Type: table.Call, Min: 1, Max: 1},
+		{Type: table.Raise, Min: 2, Max: 98},
+		{Type: table.AllIn, Min: 99, Max: 99},
+	}
+	if actions := tbl.LegalActionsDetailed(); !reflect.DeepEqual(actions, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, actions)
+	}
+	for _, a := range checkDown[:2] {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// nothing is owed after the flop
+	expected = []table.LegalAction{
+		{Type: table.Fold},
+		{Type: table.Check},
+		{Type: table.Bet, Min: 2, Max: 98},
+		{Type: table.AllIn, Min: 98, Max: 98},
+	}
+	if actions := tbl.LegalActionsDetailed(); !reflect.DeepEqual(actions, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, actions)
+	}
+}
+
+func TestPotOdds(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"}, "As", "Ks", "Qs", "Js", "2c", "Ts", "9s", "8s", "3c", "7s", "4c", "6s")
+	for _, a := range checkDown[:2] {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := tbl.State(); s.PotOdds != 0 {
+		t.Fatalf("expected no pot odds with nothing owed but got %v", s.PotOdds)
+	}
+	// a half pot bet into 4 offers a call of 2 to win 8
+	if err := tbl.Bet(2); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Pot != 6 || s.PotOdds != 0.25 {
+		t.Fatalf("expected pot odds of 0.25 with a pot of 6 but got %v with a pot of %d", s.PotOdds, s.Pot)
+	}
+}
+
+func TestContesting(t *testing.T) {
+	opts := table.Options{
+		Variant: table.TexasHoldem,
+		Limit:   table.NoLimit,
+		Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:   100,
+	}
+	tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(1))), opts, []string{"a", "b", "c", "d"})
+	if s := tbl.State(); s.Contesting != 4 {
+		t.Fatalf("expected 4 players in the hand but got %d", s.Contesting)
+	}
+	for _, expected := range []int{3, 2} {
+		if err := tbl.Fold(); err != nil {
+			t.Fatal(err)
+		}
+		if s := tbl.State(); s.Contesting != expected {
+			t.Fatalf("expected %d players left in the hand but got %d\n%v", expected, s.Contesting, s)
+		}
+	}
+	// the last fold ends the hand and everyone is dealt into the next
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Contesting != 4 {
+		t.Fatalf("expected 4 players in the next hand but got %d", s.Contesting)
+	}
+}
+
+func TestBigBlindOption(t *testing.T) {
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s"}
+	for _, raise := range []bool{false, true} {
+		tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"}, cards...)
+		// b limps and c completes the small blind
+		for _, a := range []table.Action{{table.Call, 0}, {table.Call, 0}} {
+			if err := tbl.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s := tbl.State()
+		if s.Round != table.PreFlop || s.Active.ID != "a" {
+			t.Fatalf("expected the big blind a to have the option but got %s on %v", s.Active.ID, s.Round)
+		}
+		// with nothing owed the big blind raises with a bet
+		actions := tbl.LegalActions()
+		if !includes(actions, table.Check) || !includes(actions, table.Bet) {
+			t.Fatalf("expected a to be able to check or raise but got %v", actions)
+		}
+		if !raise {
+			if err := tbl.Check(); err != nil {
+				t.Fatal(err)
+			}
+			if s := tbl.State(); s.Round != table.Flop {
+				t.Fatalf("expected the flop after the big blind checks but got %v", s.Round)
+			}
+			continue
+		}
+		if err := tbl.Bet(4); err != nil {
+			t.Fatal(err)
+		}
+		s = tbl.State()
+		if s.Round != table.PreFlop || s.Active.ID != "b" || s.Cost != 6 || s.CallAmount != 4 {
+			t.Fatalf("expected b to face a raise to 6 but got %s owing %d on %v", s.Active.ID, s.CallAmount, s.Round)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(3))), table.Options{Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Buyin: 100}, []string{"a", "b", "c"})
+	if err := tbl.Raise(4); err != nil {
+		t.Fatal(err)
+	}
+	before := tbl.State()
+	history := tbl.History()
+	clone := tbl.Clone()
+	if s := clone.State(); !reflect.DeepEqual(s, before) {
+		t.Fatalf("expected the clone to have the same state %+v but got %+v", before, s)
+	}
+	// play the hand out on the clone
+	for clone.State().Result == before.Result {
+		if err := clone.Act(table.Action{Type: clone.LegalActions()[1]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := tbl.State(); !reflect.DeepEqual(s, before) {
+		t.Fatalf("expected the table to be unchanged at %+v but got %+v", before, s)
+	}
+	if h := tbl.History(); !reflect.DeepEqual(h, history) {
+		t.Fatalf("expected the history to be unchanged at %+v but got %+v", history, h)
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the clone deals its next hand without drawing on the table's dealer,
+	// so the table deals the same cards as a twin dealt by the same source
+	twin := table.New(hand.NewDealer(rand.New(rand.NewSource(3))), table.Options{Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Buyin: 100}, []string{"a", "b", "c"})
+	if err := twin.Raise(4); err != nil {
+		t.Fatal(err)
+	}
+	playHand := func(tb *table.Table) {
+		for result := tb.State().Result; tb.State().Result == result; {
+			if err := tb.Act(table.Action{Type: tb.LegalActions()[1]}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	playHand(clone)
+	playHand(tbl)
+	playHand(twin)
+	if s, expected := tbl.State(), twin.State(); !reflect.DeepEqual(s.Seats, expected.Seats) {
+		t.Fatalf("expected the table to deal the same hand as its twin %+v but got %+v", expected.Seats, s.Seats)
+	}
+}
+
+func TestCloneLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	opts := table.Options{Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Buyin: 100, Logger: log.New(buf, "", 0)}
+	tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(3))), opts, []string{"a", "b", "c"})
+	events := 0
+	tbl.OnEvent(func(table.Event) { events++ })
+	clone := tbl.Clone()
+	if err := clone.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 || events != 0 {
+		t.Fatalf("expected actions on the clone not to be logged or published but got %q and %d events", buf.String(), events)
+	}
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "b calls\n" || events != 1 {
+		t.Fatalf("expected the table's own action to be logged and published but got %q and %d events", buf.String(), events)
+	}
+}
+
+func TestSplitPotRemainder(t *testing.T) {
+	tests := []struct {
+		ante    int
+		ids     []string
+		cards   []string
+		actions []table.ActionType
+		chips   map[string]int
+	}{
+		// b is on the button so a, as the next to win clockwise, gets
+		// the odd chip of 5
+		{
+			ids:     []string{"a", "b", "c"},
+			cards:   []string{"2c", "3d", "2d", "3h", "2h", "4c", "3c", "As", "Ks", "Qs", "5c", "Js", "6c", "Ts"},
+			actions: []table.ActionType{table.Call, table.Fold, table.Check},
+			chips:   map[string]int{"a": 3, "b": 2},
+		},
+		// b is on the button so d and a get the two spare chips of 11
+		{
+			ante:    1,
+			ids:     []string{"a", "b", "c", "d"},
+			cards:   []string{"2c", "3d", "2d", "3h", "2h", "4c", "3c", "4d", "5c", "As", "Ks", "Qs", "6c", "Js", "7c", "Ts"},
+			actions: []table.ActionType{table.Call, table.Call, table.Fold, table.Check},
+			chips:   map[string]int{"a": 4, "b": 3, "d": 4},
+		},
+	}
+	for _, test := range tests {
+		opts := table.Options{
+			Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2, Ante: test.ante},
+			Buyin:  100,
+		}
+		tbl := scriptedWith(opts, test.ids, test.cards...)
+		for _, a := range test.actions {
+			if err := tbl.Act(table.Action{Type: a}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for tbl.State().Result == nil {
+			if err := tbl.Act(table.Action{Type: table.Check}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		awarded := map[string]int{}
+		for _, e := range tbl.LastHistory() {
+			if e.Type == table.PotAwarded {
+				awarded[e.PlayerID] += e.Chips
+			}
+		}
+		if !reflect.DeepEqual(awarded, test.chips) {
+			t.Fatalf("expected the pot to be split %v but got %v", test.chips, awarded)
+		}
+	}
+}
+
+// lowestRanks is an Evaluator where the hand with the lowest ranks wins.
+type lowestRanks struct{}
+
+func (lowestRanks) Compare(a, b []hand.Card) int {
+	sum := func(cards []hand.Card) int {
+		total := 0
+		for _, c := range cards {
+			total += int(c.Rank())
+		}
+		return total
+	}
+	return sum(b) - sum(a)
+}
+
+func TestEvaluator(t *testing.T) {
+	cards := []string{"As", "Ad", "7c", "2d", "2c", "Kh", "Qh", "9s", "3c", "5c", "4c", "3h"}
+	for _, custom := range []bool{false, true} {
+		opts := table.Options{Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Buyin: 100}
+		expected := "a"
+		if custom {
+			opts.Evaluator = lowestRanks{}
+			expected = "b"
+		}
+		tbl := scriptedWith(opts, []string{"a", "b"}, cards...)
+		for _, a := range checkDown {
+			if err := tbl.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if winners := tbl.State().Result.Winners; !reflect.DeepEqual(winners, []string{expected}) {
+			t.Fatalf("expected %s to win with a custom evaluator %v but got %v", expected, custom, winners)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	opts := table.Options{Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Buyin: 100}
+	ids := []string{"a", "b", "c"}
+	tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(7))), opts, ids)
+	for _, a := range []table.Action{{table.Call, 0}, {table.Call, 0}, {table.Check, 0}, {table.Bet, 4}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := json.Marshal(tbl.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := table.Snapshot{}
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	// the restored dealer picks up where the original left off
+	dealer := hand.NewDealer(rand.New(rand.NewSource(7)))
+	dealer.Deck()
+	restored := table.Restore(dealer, snapshot)
+	if !reflect.DeepEqual(tbl.State(), restored.State()) {
+		t.Fatalf("expected restored state %+v but got %+v", tbl.State(), restored.State())
+	}
+	actions := []table.Action{{table.Call, 0}, {table.Fold, 0}}
+	for i := 0; i < 4; i++ {
+		actions = append(actions, table.Action{Type: table.Check})
+	}
+	actions = append(actions, table.Action{Type: table.Call}, table.Action{Type: table.Call}, table.Action{Type: table.Check})
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+		if err := restored.Act(a); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(tbl.State(), restored.State()) {
+			t.Fatalf("expected restored state %+v but got %+v", tbl.State(), restored.State())
+		}
+	}
+	if tbl.State().Result == nil {
+		t.Fatal("expected the hand to finish")
+	}
+}
+
+func TestAddPlayer(t *testing.T) {
+	cards := []string{"As", "Ks", "Qs", "Js", "2c", "Ts", "9s", "8s", "3c", "7s", "4c", "6s", "5s", "4s"}
+	for _, post := range []bool{false, true} {
+		opts := table.Options{
+			Stakes:      table.Stakes{SmallBlind: 1, BigBlind: 2},
+			Buyin:       100,
+			PostToEnter: post,
+		}
+		tbl := scriptedWith(opts, []string{"a", "b"}, cards...)
+		if err := tbl.AddPlayer("c"); err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.AddPlayer("a"); err == nil {
+			t.Fatal("expected an error adding a player who is already seated")
+		}
+		s := tbl.State()
+		if c := s.Seats[2]; !c.SittingOut || len(c.Cards) != 0 {
+			t.Fatalf("expected c to sit out the hand in progress but got %+v", c)
+		}
+		for _, a := range checkDown {
+			if tbl.Active().ID == "c" {
+				t.Fatal("expected c not to act in the hand in progress")
+			}
+			if err := tbl.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// c is on the button for the next hand
+		s = tbl.State()
+		c := s.Seats[2]
+		if c.SittingOut || len(c.Cards) != 2 || s.Button != 2 {
+			t.Fatalf("expected c to be dealt in on the button but got %+v", c)
+		}
+		expected := 0
+		if post {
+			expected = 2
+		}
+		if c.ChipsInPot != expected || s.Active.ID != "c" {
+			t.Fatalf("expected c to act first with %d posted but got %+v", expected, c)
+		}
+	}
+}
+
+func TestAddPlayerWaiting(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"},
+		"As", "Ad", "2c", "7d", "3h", "8s", "3c", "Ks", "Qd", "9c", "4c", "4h", "5c")
+	if err := tbl.AllIn(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.AllIn(); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Status != table.Waiting {
+		t.Fatalf("expected the table to wait for players but got %v", s.Status)
+	}
+	// c joining makes two players with chips so a hand is dealt
+	if err := tbl.AddPlayer("c"); err != nil {
+		t.Fatal(err)
+	}
+	s := tbl.State()
+	if s.Status != table.Dealing || !s.Seats[1].SittingOut {
+		t.Fatalf("expected a hand to be dealt without b but got %v", s)
+	}
+	for _, i := range []int{0, 2} {
+		if seat := s.Seats[i]; seat.SittingOut || len(seat.Cards) != 2 {
+			t.Fatalf("expected %s to be dealt in but got %+v", seat.ID, seat)
+		}
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostBlind(t *testing.T) {
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "2c", "6s", "5s", "4s", "3c", "3s", "4c", "2s"}
+	for _, post := range []bool{false, true} {
+		opts := table.Options{
+			Stakes:          table.Stakes{SmallBlind: 1, BigBlind: 2},
+			Buyin:           100,
+			WaitForBigBlind: true,
+		}
+		tbl := scriptedWith(opts, []string{"a", "b", "c"}, cards...)
+		if err := tbl.AddPlayer("d"); err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.PostBlind("a"); err == nil {
+			t.Fatal("expected an error posting to enter for a player already dealt in")
+		}
+		if post {
+			if err := tbl.PostBlind("d"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// everyone folds to the big blind each hand while d enters between
+		// the button and the small blind
+		for hand, bb := range []string{"a", "b", "c", "d"} {
+			d := tbl.State().Seats[3]
+			switch {
+			case bb == "d":
+				if d.SittingOut || d.ChipsInPot != 2 {
+					t.Fatalf("hand %d: expected d to be dealt in the big blind but got %+v", hand, d)
+				}
+			case hand == 0 || !post:
+				if !d.SittingOut {
+					t.Fatalf("hand %d: expected d to wait for the big blind but got %+v", hand, d)
+				}
+			case hand == 1:
+				if d.SittingOut || d.ChipsInPot != 2 || d.Entering {
+					t.Fatalf("hand %d: expected d to be dealt in posting a blind but got %+v", hand, d)
+				}
+			default:
+				if d.SittingOut || d.ChipsInPot != 0 {
+					t.Fatalf("hand %d: expected d to be dealt in without posting again but got %+v", hand, d)
+				}
+			}
+			result := tbl.State().Result
+			for tbl.State().Result == result {
+				if err := tbl.Fold(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if winners := tbl.State().Result.Winners; !reflect.DeepEqual(winners, []string{bb}) {
+				t.Fatalf("hand %d: expected the big blind %s to win but got %v", hand, bb, winners)
+			}
+		}
+		if err := tbl.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMaxSeats(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}
+	tests := []struct {
+		variant table.Variant
+		seats   int
+	}{
+		{table.TexasHoldem, 10},
+		{table.OmahaHi, 9},
+		{table.OmahaHiLo, 9},
+	}
+	for _, test := range tests {
+		opts := table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Variant: test.variant}
+		dealer := hand.NewDealer(rand.New(rand.NewSource(0)))
+		if _, err := table.NewWithError(dealer, opts, ids[:test.seats+1]); err == nil {
+			t.Fatalf("expected an error seating %d players at %v", test.seats+1, test.variant)
+		}
+		tbl, err := table.NewWithError(dealer, opts, ids[:test.seats-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.AddPlayer(ids[test.seats-1]); err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.AddPlayer(ids[test.seats]); err == nil {
+			t.Fatalf("expected an error adding player %d at %v", test.seats+1, test.variant)
+		}
+	}
+	opts := table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, MaxSeats: 2}
+	tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(0))), opts, []string{"a", "b"})
+	if err := tbl.AddPlayer("c"); err == nil {
+		t.Fatal("expected an error adding a third player at a table for two")
+	}
+}
+
+func TestDuplicatePlayerIDs(t *testing.T) {
+	opts := table.Options{Buyin: 100, Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}}
+	dealer := hand.NewDealer(rand.New(rand.NewSource(0)))
+	if _, err := table.NewWithError(dealer, opts, []string{"alice", "bob", "alice"}); err == nil {
+		t.Fatal("expected an error seating alice twice")
+	}
+	tbl, err := table.NewWithError(dealer, opts, []string{"alice", "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.AddPlayer("alice"); err == nil {
+		t.Fatal("expected an error adding alice again")
+	}
+	if n := len(tbl.State().Seats); n != 2 {
+		t.Fatalf("expected 2 seats but got %d", n)
+	}
+}
+
+func TestTopUp(t *testing.T) {
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s"}
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"}, cards...)
+	if err := tbl.TopUp("a", 50); err == nil {
+		t.Fatal("expected an error topping up a player in the hand")
+	}
+	// b acts first and folds, leaving a and c in the hand
+	if err := tbl.Act(table.Action{Type: table.Fold}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.TopUp("b", 0); err == nil {
+		t.Fatal("expected an error topping up no chips")
+	}
+	if err := tbl.TopUp("b", 50); err != nil {
+		t.Fatal(err)
+	}
+	if b := tbl.State().Seats[1]; b.Chips != 150 {
+		t.Fatalf("expected b to have 150 chips but got %d", b.Chips)
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	// buying in resets the stack rather than adding to it
+	if err := tbl.BuyPlayerIn("b"); err != nil {
+		t.Fatal(err)
+	}
+	if b := tbl.State().Seats[1]; b.Chips != 100 {
+		t.Fatalf("expected b to have 100 chips but got %d", b.Chips)
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTopUpBusted(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"},
+		"As", "Ad", "2c", "7d", "3h", "8s", "3c", "Ks", "Qd", "9c", "4c", "4h", "5c")
+	if err := tbl.AllIn(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.AllIn(); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Status != table.Waiting || s.Seats[1].Chips != 0 {
+		t.Fatalf("expected b to bust and the table to wait but got %v", s)
+	}
+	// b's top up deals the next hand rather than leaving the table waiting
+	if err := tbl.TopUp("b", 50); err != nil {
+		t.Fatal(err)
 	}
-)
+	s := tbl.State()
+	if b := s.Seats[1]; s.Status != table.Dealing || b.SittingOut || len(b.Cards) != 2 || chips(b) != 50 {
+		t.Fatalf("expected b to be dealt in with 50 chips but got %v", s)
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
 
-func TestTable(t *testing.T) {
-	for _, tc := range testCases {
-		tbl := tc.start
-		for _, a := range tc.actions {
+func TestMaxStack(t *testing.T) {
+	opts := table.Options{
+		Stakes:   table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:    100,
+		MaxStack: 120,
+	}
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s"}
+	tbl := scriptedWith(opts, []string{"a", "b", "c"}, cards...)
+	if err := tbl.Act(table.Action{Type: table.Fold}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.TopUp("b", 50); err != nil {
+		t.Fatal(err)
+	}
+	if b := tbl.State().Seats[1]; b.Chips != 120 {
+		t.Fatalf("expected b's top up to be capped at 120 chips but got %d", b.Chips)
+	}
+	if err := tbl.TopUp("b", 10); err == nil {
+		t.Fatal("expected an error topping up a max stack")
+	}
+	if err := tbl.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShowdownHands(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ad", "Ks", "Kd", "Kh", "Kc", "2c", "2h", "5c", "9d", "3c", "Jh", "4c", "3s")
+	actions := []table.Action{{table.Call, 0}, {table.Call, 0}}
+	for i := 0; i < 10; i++ {
+		actions = append(actions, table.Action{Type: table.Check})
+	}
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	showdown := tbl.State().Result.ShowdownHands
+	places := map[string]int{"a": 1, "b": 2, "c": 2}
+	if len(showdown) != len(places) {
+		t.Fatalf("expected hands for %d players but got %+v", len(places), showdown)
+	}
+	for id, place := range places {
+		info := showdown[id]
+		if info.Ranking != hand.Pair || info.Place != place {
+			t.Fatalf("expected %s to show a pair in place %d but got %+v", id, place, info)
+		}
+	}
+	if desc := showdown["b"].Description; desc != "pair of kings" {
+		t.Fatalf("expected b to show a pair of kings but got %s", desc)
+	}
+
+	// nothing is shown when everyone folds
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	if showdown := tbl.State().Result.ShowdownHands; showdown != nil {
+		t.Fatalf("expected no showdown hands but got %+v", showdown)
+	}
+}
+
+func TestWinningHand(t *testing.T) {
+	tests := []struct {
+		cards       []string
+		winner      string
+		ranking     hand.Ranking
+		description string
+		used        []string
+	}{
+		{[]string{"Kh", "Kd", "Ah", "Qd", "2c", "Ks", "7c", "2d", "3c", "9h", "4c", "3s"}, "a", hand.ThreeOfAKind, "three of a kind kings", []string{"Kh", "Kd", "Ks", "9h", "7c"}},
+		{[]string{"Ah", "Ad", "6h", "2h", "2c", "Kh", "9h", "4c", "3c", "3s", "5c", "Jh"}, "b", hand.Flush, "flush king high", []string{"Kh", "Jh", "9h", "6h", "2h"}},
+		{[]string{"8c", "7d", "Ac", "Kd", "2c", "6h", "5s", "4d", "3c", "Qh", "4c", "Jc"}, "a", hand.Straight, "straight eight high", []string{"8c", "7d", "6h", "5s", "4d"}},
+		{[]string{"Jc", "9d", "Qc", "Qd", "3c", "Js", "9s", "3h", "4c", "2c", "5c", "5d"}, "a", hand.TwoPair, "two pair jacks and nines", []string{"Jc", "Js", "9d", "9s", "5d"}},
+	}
+	for i, test := range tests {
+		tbl := scripted(table.TexasHoldem, []string{"a", "b"}, test.cards...)
+		for _, a := range checkDown {
 			if err := tbl.Act(a); err != nil {
 				t.Fatal(err)
 			}
 		}
-		if tc.condition(tbl.State()) == false {
-			t.Fatalf(tc.description)
+		result := tbl.State().Result
+		if !reflect.DeepEqual(result.Winners, []string{test.winner}) {
+			t.Fatalf("test %d: expected %s to win but got %v", i, test.winner, result.Winners)
+		}
+		w := result.WinningHand
+		if w == nil || w.Ranking != test.ranking || w.Description != test.description || !reflect.DeepEqual(w.Cards, jokertest.Cards(test.used...)) {
+			t.Fatalf("test %d: expected %s to win with %s using %v but got %+v", i, test.winner, test.description, test.used, w)
+		}
+	}
+	// no hand wins without a showdown
+	tbl := scripted(table.TexasHoldem, []string{"a", "b"}, "As", "Ks", "Qs", "Js", "2c", "Ts", "9s", "8s", "3c", "7s", "4c", "6s")
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	if w := tbl.State().Result.WinningHand; w != nil {
+		t.Fatalf("expected no winning hand without a showdown but got %+v", w)
+	}
+}
+
+func TestActionErrors(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"}, "As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s")
+	if err := tbl.Raise(3); err != nil {
+		t.Fatal(err)
+	}
+	// c has the small blind in so owes 4
+	err := tbl.Check()
+	if err == nil || !strings.Contains(err.Error(), "4 chips are owed") {
+		t.Fatalf("expected an error saying 4 chips are owed but got %v", err)
+	}
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	err = tbl.Call()
+	if err == nil || !strings.Contains(err.Error(), "nothing is owed") {
+		t.Fatalf("expected an error saying nothing is owed but got %v", err)
+	}
+}
+
+func TestBlindsPosted(t *testing.T) {
+	type posted struct {
+		typ   table.EventType
+		id    string
+		chips int
+	}
+	cards := []string{"As", "Ks", "Qs", "Js", "Ts", "9s", "8s", "7s", "6s", "5s", "4s"}
+	tests := []struct {
+		opts     table.Options
+		ids      []string
+		events   []posted
+		first    string
+		minRaise int
+	}{
+		{
+			table.Options{Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Buyin: 100},
+			[]string{"a", "b", "c"},
+			[]posted{{table.BlindPosted, "c", 1}, {table.BlindPosted, "a", 2}, {table.CardsDealt, "a", 0}, {table.CardsDealt, "b", 0}, {table.CardsDealt, "c", 0}},
+			"b", 2,
+		},
+		{
+			table.Options{Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2}, Buyin: 100},
+			[]string{"a", "b"},
+			[]posted{{table.BlindPosted, "b", 1}, {table.BlindPosted, "a", 2}, {table.CardsDealt, "a", 0}, {table.CardsDealt, "b", 0}},
+			"b", 2,
+		},
+		{
+			table.Options{Stakes: table.Stakes{SmallBlind: 1, BigBlind: 2, Ante: 1}, Buyin: 100, AllowStraddle: true},
+			[]string{"a", "b", "c"},
+			[]posted{{table.AntePosted, "a", 1}, {table.AntePosted, "b", 1}, {table.AntePosted, "c", 1}, {table.BlindPosted, "c", 1}, {table.BlindPosted, "a", 2},
+				{table.CardsDealt, "a", 0}, {table.CardsDealt, "b", 0}, {table.CardsDealt, "c", 0}, {table.StraddlePosted, "b", 4}},
+			"c", 4,
+		},
+	}
+	for i, test := range tests {
+		tbl := scriptedWith(test.opts, test.ids, cards...)
+		events := []posted{}
+		for _, e := range tbl.History()[1:] {
+			events = append(events, posted{e.Type, e.PlayerID, e.Chips})
+		}
+		if !reflect.DeepEqual(events, test.events) {
+			t.Fatalf("test %d: expected %v but got %v", i, test.events, events)
+		}
+		if s := tbl.State(); s.Active.ID != test.first || s.MinRaise != test.minRaise {
+			t.Fatalf("test %d: expected %s to act first facing a min raise of %d but got %s and %d", i, test.first, test.minRaise, s.Active.ID, s.MinRaise)
+		}
+	}
+}
+
+func TestMuck(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ad", "Ks", "Kd", "Qh", "Qc", "2c", "2h", "5c", "9d", "3c", "Jh", "4c", "3s")
+	// a wins so has to show even after mucking
+	for _, id := range []string{"a", "c"} {
+		if err := tbl.Muck(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	actions := []table.Action{{table.Call, 0}, {table.Call, 0}}
+	for i := 0; i < 10; i++ {
+		actions = append(actions, table.Action{Type: table.Check})
+	}
+	for _, a := range actions {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result := tbl.State().Result
+	if _, ok := result.Shown["c"]; ok {
+		t.Fatalf("expected c's mucked cards to be hidden but got %v", result.Shown)
+	}
+	if _, ok := result.ShowdownHands["c"]; ok {
+		t.Fatalf("expected c's mucked hand to be hidden but got %v", result.ShowdownHands)
+	}
+	if len(result.Shown["a"]) != 2 || len(result.Shown["b"]) != 2 {
+		t.Fatalf("expected a and b to show but got %v", result.Shown)
+	}
+
+	// the winner doesn't show when everyone folds unless they choose to
+	// and neither does a player who folds
+	if err := tbl.Show("c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	result = tbl.State().Result
+	if len(result.Shown) != 1 || len(result.Shown["c"]) != 2 {
+		t.Fatalf("expected only c to show but got %v", result.Shown)
+	}
+	if err := tbl.Muck("z"); err == nil {
+		t.Fatal("expected an error mucking for a player not at the table")
+	}
+}
+
+func TestShowdownOrder(t *testing.T) {
+	for _, autoMuck := range []bool{false, true} {
+		opts := table.Options{
+			Variant:  table.TexasHoldem,
+			Limit:    table.NoLimit,
+			Stakes:   table.Stakes{SmallBlind: 1, BigBlind: 2},
+			Buyin:    100,
+			AutoMuck: autoMuck,
+		}
+		// a has aces, b kings and c queens
+		tbl := scriptedWith(opts, []string{"a", "b", "c"},
+			"As", "Ad", "Ks", "Kd", "Qh", "Qc", "2c", "2h", "5c", "9d", "3c", "Jh", "4c", "3s")
+		// everyone checks to b on the river
+		actions := []table.Action{{table.Call, 0}, {table.Call, 0}, {table.Check, 0}}
+		for i := 0; i < 8; i++ {
+			actions = append(actions, table.Action{Type: table.Check})
+		}
+		for _, a := range actions {
+			if err := tbl.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// with no bet on the river the first to act shows first
+		if s := tbl.State(); s.Round != table.River || !reflect.DeepEqual(tbl.ShowdownOrder(), []string{"c", "a", "b"}) {
+			t.Fatalf("expected c to show first on the river but got %v\n%v", tbl.ShowdownOrder(), s)
+		}
+		// b bets the river and shows first, then c and a
+		if err := tbl.Bet(10); err != nil {
+			t.Fatal(err)
+		}
+		if order := tbl.ShowdownOrder(); !reflect.DeepEqual(order, []string{"b", "c", "a"}) {
+			t.Fatalf("expected the bettor to show first but got %v", order)
+		}
+		for _, a := range []table.Action{{table.Call, 0}, {table.Call, 0}} {
+			if err := tbl.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// c's queens are beaten by b's kings so c mucks with AutoMuck,
+		// while a wins with aces and shows
+		expected := []string{"b", "c", "a"}
+		if autoMuck {
+			expected = []string{"b", "a"}
+		}
+		result := tbl.State().Result
+		if !reflect.DeepEqual(result.ShowdownOrder, expected) {
+			t.Fatalf("auto muck %v: expected the players to show in the order %v but got %v", autoMuck, expected, result.ShowdownOrder)
+		}
+		if _, ok := result.Shown["c"]; ok == autoMuck {
+			t.Fatalf("auto muck %v: expected c to show only without auto muck but got %v", autoMuck, result.Shown)
+		}
+		if !reflect.DeepEqual(result.Winners, []string{"a"}) {
+			t.Fatalf("expected a to win but got %v", result.Winners)
+		}
+	}
+}
+
+func TestStateFor(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ad", "Ks", "Kd", "Qh", "Qc", "2c", "2h", "5c", "9d", "3c", "Jh", "4c", "3s")
+	s := tbl.StateFor("a")
+	for _, seat := range s.Seats {
+		if seat.ID == "a" && !reflect.DeepEqual(seat.Cards, jokertest.Cards("As", "Ad")) {
+			t.Fatalf("expected a to see their own cards but got %v", seat.Cards)
+		}
+		if seat.ID != "a" && seat.Cards != nil {
+			t.Fatalf("expected %s's cards to be hidden from a but got %v", seat.ID, seat.Cards)
+		}
+	}
+	if s.Active.ID != "b" || s.Active.Cards != nil {
+		t.Fatalf("expected the active player b's cards to be hidden from a but got %v", s.Active.Cards)
+	}
+	if s := tbl.StateFor("b"); !reflect.DeepEqual(s.Active.Cards, jokertest.Cards("Ks", "Kd")) {
+		t.Fatalf("expected b to see their own cards but got %v", s.Active.Cards)
+	}
+	if s := tbl.State(); len(s.Seats[1].Cards) != 2 {
+		t.Fatal("expected the full state to still have every player's cards")
+	}
+}
+
+func TestView(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ad", "Ks", "Kd", "Qh", "Qc", "2c", "2h", "5c", "9d", "3c", "Jh", "4c", "3s")
+	hidden := []table.ViewCard{{Hidden: true}, {Hidden: true}}
+	visible := func(cards ...string) []table.ViewCard {
+		view := []table.ViewCard{}
+		for _, c := range jokertest.Cards(cards...) {
+			view = append(view, table.ViewCard{Card: c})
+		}
+		return view
+	}
+	expected := []table.PlayerView{{"a", visible("As", "Ad")}, {"b", hidden}, {"c", hidden}}
+	if view := tbl.View("a"); !reflect.DeepEqual(view, expected) {
+		t.Fatalf("expected a to see only their own cards before the flop but got %+v", view)
+	}
+	for _, a := range []table.Action{{table.Call, 0}, {table.Call, 0}, {table.Check, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if view := tbl.View("a"); !reflect.DeepEqual(view, expected) {
+		t.Fatalf("expected a to see only their own cards on the flop but got %+v", view)
+	}
+	expected = []table.PlayerView{{"a", hidden}, {"b", hidden}, {"c", hidden}}
+	if view := tbl.View(""); !reflect.DeepEqual(view, expected) {
+		t.Fatalf("expected spectators to see no cards but got %+v", view)
+	}
+	// c mucks their losing hand at showdown
+	if err := tbl.Muck("c"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 9; i++ {
+		if err := tbl.Act(table.Action{Type: table.Check}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected = []table.PlayerView{{"a", visible("As", "Ad")}, {"b", visible("Ks", "Kd")}, {"c", hidden}}
+	if view := tbl.LastView(""); !reflect.DeepEqual(view, expected) {
+		t.Fatalf("expected the cards shown at showdown to be visible but got %+v", view)
+	}
+	if view := tbl.LastView("c"); !reflect.DeepEqual(view[2], table.PlayerView{"c", visible("Qh", "Qc")}) {
+		t.Fatalf("expected c to see their own mucked cards but got %+v", view)
+	}
+
+	// up cards in stud are seen by everyone
+	opts := table.Options{
+		Variant: table.SevenCardStud,
+		Stakes:  table.Stakes{BigBlind: 2, BringIn: 1},
+		Buyin:   100,
+	}
+	tbl = scriptedWith(opts, []string{"a", "b"}, "As", "Ks", "9h", "Qd", "Jd", "2c", "Ah", "3c")
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Check(); err != nil {
+		t.Fatal(err)
+	}
+	b := table.PlayerView{"b", []table.ViewCard{{Hidden: true}, {Hidden: true}, {Card: hand.TwoClubs}, {Card: hand.ThreeClubs}}}
+	if view := tbl.View("a"); len(view) != 2 || !reflect.DeepEqual(view[1], b) {
+		t.Fatalf("expected a to see b's up cards but got %+v", view)
+	}
+}
+
+func TestActionClosesOnRaiser(t *testing.T) {
+	tbl := threePerson100Buyin()
+	// b raises before the flop and c and a call, and on the flop a raises
+	// c's bet and b and c call
+	rounds := []struct {
+		actions []table.Action
+		next    table.Round
+	}{
+		{[]table.Action{{table.Raise, 4}, {table.Call, 0}, {table.Call, 0}}, table.Flop},
+		{[]table.Action{{table.Bet, 2}, {table.Raise, 2}, {table.Call, 0}, {table.Call, 0}}, table.Turn},
+	}
+	for _, round := range rounds {
+		for _, a := range round.actions {
+			if err := tbl.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if s := tbl.State(); s.Round != round.next {
+			t.Fatalf("expected the action to close on the raiser and move to the %v but got %s to act on the %v", round.next, s.Active.ID, s.Round)
+		}
+	}
+}
+
+func TestLastAggressor(t *testing.T) {
+	tbl := threePerson100Buyin()
+	if p := tbl.LastAggressor(); p != nil {
+		t.Fatalf("expected the blinds not to count as aggression but got %s", p.ID)
+	}
+	// b raises, c reraises and a and b call before the flop, then on the
+	// flop c checks, a bets and b raises all in
+	steps := []struct {
+		action    table.Action
+		aggressor string
+	}{
+		{table.Action{Type: table.Raise, Chips: 4}, "b"},
+		{table.Action{Type: table.Raise, Chips: 6}, "c"},
+		{table.Action{Type: table.Call}, "c"},
+		{table.Action{Type: table.Call}, ""},
+		{table.Action{Type: table.Check}, ""},
+		{table.Action{Type: table.Bet, Chips: 10}, "a"},
+		{table.Action{Type: table.AllIn}, "b"},
+	}
+	for i, step := range steps {
+		if err := tbl.Act(step.action); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		p := tbl.LastAggressor()
+		if step.aggressor == "" && p != nil || step.aggressor != "" && (p == nil || p.ID != step.aggressor) {
+			t.Fatalf("step %d: expected the last aggressor to be %q but got %+v", i, step.aggressor, p)
+		}
+	}
+	if p := tbl.Clone().LastAggressor(); p == nil || p.ID != "b" {
+		t.Fatalf("expected the last aggressor to be kept in snapshots but got %+v", p)
+	}
+}
+
+func TestCanAct(t *testing.T) {
+	tbl := threePerson100Buyin()
+	before := tbl.State()
+	legal := []table.Action{{table.Fold, 0}, {table.Call, 0}, {table.Raise, 2}, {table.Raise, 97}, {table.AllIn, 0}}
+	for _, a := range legal {
+		if err := tbl.CanAct(a); err != nil {
+			t.Fatalf("expected %v to be legal but got %v", a, err)
+		}
+	}
+	illegal := []table.Action{{table.Check, 0}, {table.Bet, 2}, {table.Raise, 1}, {table.Raise, 99}}
+	for _, a := range illegal {
+		if err := tbl.CanAct(a); err == nil {
+			t.Fatalf("expected %v to be illegal", a)
+		}
+	}
+	if after := tbl.State(); !reflect.DeepEqual(before, after) || len(tbl.History()) != 6 {
+		t.Fatalf("expected checking actions to leave the table unchanged but got %+v", after)
+	}
+	// the pot limit is checked too
+	tbl = threePersonPotLimit()
+	if err := tbl.CanAct(table.Action{Type: table.Raise, Chips: 6}); err == nil {
+		t.Fatal("expected a raise over the pot to be illegal")
+	}
+	if err := tbl.CanAct(table.Action{Type: table.Raise, Chips: 5}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBetLimits(t *testing.T) {
+	tests := []struct {
+		limit   table.Limit
+		actions map[table.Action]bool
+	}{
+		// b owes the big blind of 2 with 100 chips
+		{table.NoLimit, map[table.Action]bool{
+			{table.Raise, 1}:  false,
+			{table.Raise, 2}:  true,
+			{table.Raise, 97}: true,
+			{table.Raise, 98}: true,
+			{table.Raise, 99}: false,
+			{table.AllIn, 0}:  true,
+		}},
+		// the pot is 5 after b calls
+		{table.PotLimit, map[table.Action]bool{
+			{table.Raise, 1}: false,
+			{table.Raise, 2}: true,
+			{table.Raise, 5}: true,
+			{table.Raise, 6}: false,
+			{table.AllIn, 0}: false,
+		}},
+	}
+	for _, test := range tests {
+		tbl := threePerson100Buyin()
+		if test.limit == table.PotLimit {
+			tbl = threePersonPotLimit()
+		}
+		for a, legal := range test.actions {
+			if err := tbl.CanAct(a); legal && err != nil || !legal && err == nil {
+				t.Fatalf("%v: expected %v to be legal %v but got %v", test.limit, a, legal, err)
+			}
+		}
+	}
+	err := threePersonPotLimit().CanAct(table.Action{Type: table.Raise, Chips: 6})
+	if err == nil || !strings.Contains(err.Error(), "limit of 5 chips") {
+		t.Fatalf("expected the pot limit in the error but got %v", err)
+	}
+}
+
+func TestFixedLimit(t *testing.T) {
+	opts := table.Options{
+		Variant: table.TexasHoldem,
+		Limit:   table.FixedLimit,
+		Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:   100,
+	}
+	tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(42))), opts, []string{"a", "b", "c"})
+	// raises before the flop are the small bet of 2
+	for _, chips := range []int{1, 3, 4} {
+		if err := tbl.CanAct(table.Action{Type: table.Raise, Chips: chips}); err == nil {
+			t.Fatalf("expected a raise of %d to be illegal", chips)
+		}
+	}
+	if err := tbl.CanAct(table.Action{Type: table.AllIn}); err == nil {
+		t.Fatal("expected going all in for more than the bet to be illegal")
+	}
+	// the big blind and three raises cap the betting
+	for _, id := range []string{"b", "c", "a"} {
+		if err := tbl.Raise(2); err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+	}
+	s := tbl.State()
+	if s.Active.ID != "b" || s.Cost != 8 {
+		t.Fatalf("expected b to face a cost of 8 but got %d for %s", s.Cost, s.Active.ID)
+	}
+	if actions := tbl.LegalActions(); !reflect.DeepEqual(actions, []table.ActionType{table.Fold, table.Call}) {
+		t.Fatalf("expected only fold or call once capped but got %v", actions)
+	}
+	for i := 0; i < 2; i++ {
+		if err := tbl.Call(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// bets stay the small bet on the flop and double on the turn
+	for _, round := range []table.Round{table.Flop, table.Turn} {
+		bet := 2
+		if round == table.Turn {
+			bet = 4
+		}
+		if s := tbl.State(); s.Round != round || s.MinRaise != bet || s.MaxRaise != bet {
+			t.Fatalf("expected bets of %d on the %v but got %d to %d on the %v", bet, round, s.MinRaise, s.MaxRaise, s.Round)
+		}
+		if err := tbl.CanAct(table.Action{Type: table.Bet, Chips: bet * 2}); err == nil {
+			t.Fatalf("expected a bet of %d to be illegal on the %v", bet*2, round)
+		}
+		if err := tbl.Bet(bet); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := tbl.Call(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// the turn is the big bet after a limped pot and a checked flop
+	tbl = table.New(hand.NewDealer(rand.New(rand.NewSource(42))), opts, []string{"a", "b", "c"})
+	for _, a := range []table.Action{{table.Call, 0}, {table.Call, 0}, {table.Check, 0}, {table.Check, 0}, {table.Check, 0}, {table.Check, 0}} {
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := tbl.State(); s.Round != table.Turn || s.MinRaise != 4 || s.MaxRaise != 4 {
+		t.Fatalf("expected bets of 4 on the turn after a limped pot but got %d to %d on the %v", s.MinRaise, s.MaxRaise, s.Round)
+	}
+
+	// the first bet in stud completes the bring in
+	opts.Variant = table.SevenCardStud
+	opts.Stakes = table.Stakes{BigBlind: 2, BringIn: 1}
+	tbl = scriptedWith(opts, []string{"a", "b", "c"},
+		"As", "Ks", "9h", "Qd", "Jd", "2c", "8c", "7c", "2d", "Ah", "3c", "2h", "Kh", "4c", "8h", "9c", "5c", "7h", "3s", "6c", "Th")
+	if err := tbl.CanAct(table.Action{Type: table.Raise, Chips: 2}); err == nil {
+		t.Fatal("expected a raise past the small bet to be illegal")
+	}
+	if err := tbl.Raise(1); err != nil {
+		t.Fatal(err)
+	}
+	if s := tbl.State(); s.Cost != 2 || s.MinRaise != 2 {
+		t.Fatalf("expected the bring in to be completed to 2 with raises of 2 but got %d and %d", s.Cost, s.MinRaise)
+	}
+}
+
+func TestMaxRaisesPerRound(t *testing.T) {
+	for _, limit := range []table.Limit{table.NoLimit, table.FixedLimit} {
+		opts := table.Options{
+			Variant:           table.TexasHoldem,
+			Limit:             limit,
+			Stakes:            table.Stakes{SmallBlind: 1, BigBlind: 2},
+			Buyin:             100,
+			MaxRaisesPerRound: 1,
 		}
+		tbl := table.New(hand.NewDealer(rand.New(rand.NewSource(42))), opts, []string{"a", "b", "c"})
+		// b's raise of the big blind is the only one allowed
+		if err := tbl.Raise(2); err != nil {
+			t.Fatal(err)
+		}
+		expectCapped := func(id string) {
+			s := tbl.State()
+			if s.Active.ID != id {
+				t.Fatalf("%v: expected %s to act but got %s", limit, id, s.Active.ID)
+			}
+			if actions := tbl.LegalActions(); !reflect.DeepEqual(actions, []table.ActionType{table.Fold, table.Call}) {
+				t.Fatalf("%v: expected %s to only fold or call but got %v", limit, id, actions)
+			}
+			if err := tbl.Raise(2); err == nil {
+				t.Fatalf("%v: expected an error raising past the cap", limit)
+			}
+		}
+		expectCapped("c")
+		if err := tbl.Call(); err != nil {
+			t.Fatal(err)
+		}
+		expectCapped("a")
+		if err := tbl.Call(); err != nil {
+			t.Fatal(err)
+		}
+		// the count starts over on the flop
+		if err := tbl.Bet(2); err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.Raise(2); err != nil {
+			t.Fatal(err)
+		}
+		expectCapped("b")
+	}
+}
+
+func TestSpectatorState(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"As", "Ad", "Ks", "Kd", "Qh", "Qc", "2c", "2h", "5c", "9d", "3c", "Jh", "4c", "3s")
+	actions := []table.Action{{table.Call, 0}, {table.Call, 0}, {table.Check, 0}}
+	for i := 0; i < 9; i++ {
+		actions = append(actions, table.Action{Type: table.Check})
+	}
+	for _, a := range actions {
+		s := tbl.SpectatorState()
+		for _, seat := range s.Seats {
+			if seat.Cards != nil {
+				t.Fatalf("expected %s's cards to be hidden from spectators but got %v", seat.ID, seat.Cards)
+			}
+		}
+		if s.Active.Cards != nil {
+			t.Fatalf("expected the active player's cards to be hidden from spectators but got %v", s.Active.Cards)
+		}
+		if full := tbl.State(); !reflect.DeepEqual(s.Cards, full.Cards) || s.Pot != full.Pot || s.Active.ID != full.Active.ID {
+			t.Fatalf("expected spectators to see the board, pot and action but got %+v", s)
+		}
+		if err := tbl.Act(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the cards shown at showdown are revealed once the hand is over
+	if shown := tbl.SpectatorState().Result.Shown; !reflect.DeepEqual(shown["a"], jokertest.Cards("As", "Ad")) {
+		t.Fatalf("expected a's cards to be shown but got %v", shown)
+	}
+}
+
+func TestDefaultingAllIn(t *testing.T) {
+	tbl := scripted(table.TexasHoldem, []string{"a", "b", "c"},
+		"7s", "2d", "9c", "8d", "As", "Ad", "2c", "Kh", "Qc", "5d", "3c", "4s", "4c", "3h")
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.AllIn(); err != nil {
+		t.Fatal(err)
+	}
+	// c disconnects after going all in but still plays out the hand
+	if err := tbl.SetPlayerDefaulting("c", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Call(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Fold(); err != nil {
+		t.Fatal(err)
+	}
+	// c won 202 and then folded the small blind on the button heads up
+	// before b posted the small blind on the next hand
+	s := tbl.State()
+	for i, expected := range []int{0, 99, 201} {
+		if chips(s.Seats[i]) != expected {
+			t.Fatalf("expected %s to have %d chips but got %d", s.Seats[i].ID, expected, chips(s.Seats[i]))
+		}
+	}
+	if s.Active.ID != "b" {
+		t.Fatalf("expected b to act but got %s", s.Active.ID)
+	}
+}
+
+func TestPotLimit(t *testing.T) {
+	tbl := threePersonPotLimit()
+	if err := tbl.Raise(6); err == nil {
+		t.Fatal("expected raise of 6 into a pot of 3 facing 2 to exceed the pot limit")
+	}
+	for _, a := range tbl.LegalActions() {
+		if a == table.AllIn {
+			t.Fatal("expected all in to be illegal when the stack exceeds the pot")
+		}
+	}
+	if err := tbl.Raise(5); err != nil {
+		t.Fatal(err)
+	}
+	if tbl.State().Cost != 7 {
+		t.Fatalf("expected cost of 7 after a pot sized raise but got %d", tbl.State().Cost)
 	}
 }
 
@@ -80,3 +3717,48 @@ func threePerson100Buyin() *table.Table {
 	ids := []string{"a", "b", "c"}
 	return table.New(dealer, opts, ids)
 }
+
+func threePersonPotLimit() *table.Table {
+	src := rand.NewSource(42)
+	r := rand.New(src)
+	dealer := hand.NewDealer(r)
+	opts := table.Options{
+		Variant: table.TexasHoldem,
+		Limit:   table.PotLimit,
+		Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:   100,
+	}
+	ids := []string{"a", "b", "c"}
+	return table.New(dealer, opts, ids)
+}
+
+// scripted returns a table with 100 chip stacks and 1/2 blinds that deals
+// the given cards in order.
+func scripted(v table.Variant, ids []string, cards ...string) *table.Table {
+	opts := table.Options{
+		Variant: v,
+		Limit:   table.NoLimit,
+		Stakes:  table.Stakes{SmallBlind: 1, BigBlind: 2},
+		Buyin:   100,
+	}
+	return scriptedWith(opts, ids, cards...)
+}
+
+func scriptedWith(opts table.Options, ids []string, cards ...string) *table.Table {
+	dealer := jokertest.Dealer(jokertest.Cards(cards...))
+	return table.New(dealer, opts, ids)
+}
+
+// chips returns the player's chips including those already in the pot.
+func chips(p table.Player) int {
+	return p.Chips + p.ChipsInPot
+}
+
+func includes(actions []table.ActionType, a table.ActionType) bool {
+	for _, action := range actions {
+		if action == a {
+			return true
+		}
+	}
+	return false
+}